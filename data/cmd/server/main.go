@@ -10,6 +10,7 @@ import (
 	"github.com/go-mockingcode/data/internal/database"
 	"github.com/go-mockingcode/data/internal/handler"
 	"github.com/go-mockingcode/data/internal/middleware"
+	"github.com/go-mockingcode/data/internal/pkg/project"
 	"github.com/go-mockingcode/data/internal/repository"
 	"github.com/go-mockingcode/data/internal/service"
 	applogger "github.com/go-mockingcode/logger"
@@ -57,15 +58,59 @@ func main() {
 		}
 	}()
 
+	// Init Clients
+	projectClient := project.NewProjectClient(cfg.ProjectServiceURL, cfg.ProjectCacheTTL)
+
 	// Init Repositories
-	docRepo := repository.NewDocumentRepository(client, cfg.MongoDBName)
+	if err := repository.MigrateInternalCollections(client, cfg.MongoDBName); err != nil {
+		log.Fatal("Failed to migrate internal collections:", err)
+	}
+	docRepo := repository.NewDocumentRepository(client, cfg.MongoDBName, cfg.HistoryTTL)
+	if err := docRepo.InitHistoryIndexes(); err != nil {
+		log.Fatal("Failed to init history indexes:", err)
+	}
+	idempotencyRepo := repository.NewIdempotencyRepository(client, cfg.MongoDBName)
+	if err := idempotencyRepo.InitIndexes(); err != nil {
+		log.Fatal("Failed to init idempotency indexes:", err)
+	}
+	snapshotRepo := repository.NewSnapshotRepository(client, cfg.MongoDBName, docRepo, cfg.SnapshotRestoreWithoutTransaction)
+	if err := snapshotRepo.InitIndexes(); err != nil {
+		log.Fatal("Failed to init snapshot indexes:", err)
+	}
+	webhookRepo := repository.NewWebhookRepository(client, cfg.MongoDBName)
+	if err := webhookRepo.InitIndexes(); err != nil {
+		log.Fatal("Failed to init webhook delivery indexes:", err)
+	}
+	// Повторные попытки доставки, прерванные перезапуском, уже не выполнятся
+	if abandoned, err := webhookRepo.FailPending(); err != nil {
+		logger.Warn("Failed to close pending webhook deliveries", slog.String("error", err.Error()))
+	} else if abandoned > 0 {
+		logger.Warn("Pending webhook deliveries marked as failed", slog.Int64("count", abandoned))
+	}
 
 	// Init Services
 	docService := service.NewDocumentService(docRepo, cfg.MaxDocumentsPerCollection)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyTTL, cfg.IdempotencyLease)
+	snapshotService := service.NewSnapshotService(projectClient, snapshotRepo)
+	webhookService := service.NewWebhookService(
+		projectClient,
+		webhookRepo,
+		cfg.WebhookMaxAttempts,
+		cfg.WebhookRetryBackoff,
+		cfg.WebhookTimeout,
+		cfg.WebhookLogTTL,
+		cfg.WebhookAllowLocal,
+	)
+	changeFeed := service.NewChangeFeed()
+	docRepo.OnChange(webhookService.HandleChanges)
+	docRepo.OnChange(changeFeed.HandleChanges)
 
 	// Init Handlers
-	docHandler := handler.NewDocumentHandler(docService)
+	docHandler := handler.NewDocumentHandler(docService, idempotencyService, changeFeed, projectClient)
 	generatorHandler := handler.NewGeneratorHandler()
+	snapshotHandler := handler.NewSnapshotHandler(snapshotService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	graphqlHandler := handler.NewGraphQLHandler(docService, projectClient)
 
 	// Route Settings
 	mux := http.NewServeMux()
@@ -74,6 +119,10 @@ func main() {
 	mux.HandleFunc("/health", healthHandler)
 	mux.HandleFunc("/generate", generatorHandler.HandleGenerate)
 
+	mux.Handle("/_snapshots", snapshotHandler)
+	mux.Handle("/_snapshots/", snapshotHandler)
+	mux.Handle("/_webhooks/", webhookHandler)
+	mux.HandleFunc("/graphql", graphqlHandler.HandleGraphQL)
 	mux.Handle("/", docHandler)
 
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
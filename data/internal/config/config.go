@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-mockingcode/data/internal/pkg/env"
@@ -13,13 +14,33 @@ type DataConfig struct {
 	MongoDBName    string
 	MongoDBTimeout time.Duration
 
-	ProjectPort string
+	ProjectPort       string
+	ProjectServiceURL string
+	ProjectCacheTTL   time.Duration // Срок кэширования настроек проекта (маршруты, вебхуки, схемы для GraphQL)
 
 	MaxDocumentsPerCollection int
 	DefaultGenerationCount    int
+	IdempotencyTTL            time.Duration
+	IdempotencyLease          time.Duration // Срок резервирования ключа выполняющимся запросом
+	HistoryTTL                time.Duration // Срок хранения истории изменений документов (0 - бессрочно)
+
+	SnapshotRestoreWithoutTransaction bool // Восстанавливать снимки без транзакций, если MongoDB без replica set
+
+	WebhookMaxAttempts  int           // Попыток доставки вебхука, включая первую
+	WebhookRetryBackoff time.Duration // Пауза перед первой повторной попыткой, далее удваивается
+	WebhookTimeout      time.Duration // Таймаут запроса к получателю
+	WebhookLogTTL       time.Duration // Срок хранения журнала доставок
+	WebhookAllowLocal   bool          // Разрешить получателей на loopback и в частных сетях
 }
 
 func Load() *DataConfig {
+	// Support both direct URL env var (for Docker) and port-based (for local dev)
+	projectPort := env.GetString("PROJECT_PORT", "8082")
+	projectURL := env.GetString("PROJECT_SERVICE_URL", "")
+	if projectURL == "" {
+		projectURL = fmt.Sprintf("http://localhost:%s", projectPort)
+	}
+
 	return &DataConfig{
 		// Server
 		ServerPort: env.GetString("PORT", "8083"),
@@ -30,10 +51,25 @@ func Load() *DataConfig {
 		MongoDBTimeout: env.GetDuration("MONGO_TIMEOUT", 10*time.Second),
 
 		// External services
-		ProjectPort: env.GetString("PROJECT_PORT", "8082"),
+		ProjectPort:       projectPort,
+		ProjectServiceURL: projectURL,
+		ProjectCacheTTL:   env.GetDuration("DATA_PROJECT_CACHE_TTL", 30*time.Second),
 
 		// Application settings
 		MaxDocumentsPerCollection: env.GetInt("DATA_MAX_DOCS_PER_COLLECTION", 500),
 		DefaultGenerationCount:    env.GetInt("DATA_DEFAULT_GENERATION_COUNT", 10),
+		IdempotencyTTL:            env.GetDuration("DATA_IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyLease:          env.GetDuration("DATA_IDEMPOTENCY_LEASE", time.Minute),
+		HistoryTTL:                env.GetDuration("DATA_HISTORY_TTL", 7*24*time.Hour),
+
+		// Snapshots
+		SnapshotRestoreWithoutTransaction: env.GetBool("DATA_SNAPSHOT_RESTORE_WITHOUT_TRANSACTION", false),
+
+		// Webhooks
+		WebhookMaxAttempts:  env.GetInt("DATA_WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookRetryBackoff: env.GetDuration("DATA_WEBHOOK_RETRY_BACKOFF", 5*time.Second),
+		WebhookTimeout:      env.GetDuration("DATA_WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookLogTTL:       env.GetDuration("DATA_WEBHOOK_LOG_TTL", 7*24*time.Hour),
+		WebhookAllowLocal:   env.GetBool("DATA_WEBHOOK_ALLOW_LOCAL", false),
 	}
 }
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/service"
+	"github.com/go-mockingcode/models"
+)
+
+// maxImportSize ограничение размера загружаемого CSV (NDJSON читается потоком без ограничения)
+const maxImportSize = 10 << 20
+
+// wantsCSV проверяет, запрошен ли список документов в CSV (?format=csv или Accept: text/csv)
+func wantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(accept))
+		if mediaType == "text/csv" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCSV записывает документы в CSV как файл для скачивания
+func writeCSV(w http.ResponseWriter, collectionName string, schema *models.Collection, docs []map[string]interface{}) {
+	var buf bytes.Buffer
+	if err := service.WriteCSV(&buf, schema, docs); err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, "Failed to encode CSV")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", collectionName+".csv"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// HandleImport godoc
+// @Summary Import documents from CSV or NDJSON
+// @Description Create documents from a CSV or NDJSON file. NDJSON (application/x-ndjson): one JSON object per line, the stream is processed in batches without loading it into memory; results contain only failed lines, index is the line number starting from 0. CSV: the first row holds field names (dot notation for nested objects: address.city), empty cells are skipped. Values are coerced to the types of the collection schema fields (reference fields with cardinality many accept comma separated ids or a JSON array); fields without schema get numbers, true/false, JSON arrays and objects or strings. The body is the CSV itself (text/csv) or a multipart form with the file in the "file" field. Rows are created like _bulk create operations; result index is the data row number starting from 0
+// @Tags documents
+// @Accept text/csv
+// @Accept mpfd
+// @Accept application/x-ndjson
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Collection Name"
+// @Param file formData file false "CSV file (multipart upload)"
+// @Success 200 {object} model.BulkResponse "Per-row results with HTTP-like status codes"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 405 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /{api_key}/{collection}/_import [post]
+func (h *DocumentHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	project, collectionName, err := extractProjectAndCollection(w, r)
+	if err != nil {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ndjsonTypes[mediaType] {
+		h.importNDJSON(w, r, project, collectionName)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var body io.Reader = r.Body
+	if mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeErrorJson(w, http.StatusBadRequest, "CSV file is required in the \"file\" form field")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
+	if err != nil {
+		return
+	}
+
+	response, err := h.docService.ImportCSV(project.ID, collectionName, schema, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrorJson(w, http.StatusRequestEntityTooLarge, "CSV is too large")
+			return
+		}
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, response)
+}
@@ -3,41 +3,162 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"mime"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-mockingcode/data/internal/model"
 	"github.com/go-mockingcode/data/internal/pkg/context"
 	"github.com/go-mockingcode/data/internal/pkg/project"
 	"github.com/go-mockingcode/data/internal/service"
+	"github.com/go-mockingcode/models"
 )
 
 type DocumentHandler struct {
-	docService *service.DocumentService
+	docService    *service.DocumentService
+	idempotency   *service.IdempotencyService
+	feed          *service.ChangeFeed
+	projectClient *project.ProjectClient
 }
 
-func NewDocumentHandler(docService *service.DocumentService) *DocumentHandler {
+func NewDocumentHandler(docService *service.DocumentService, idempotency *service.IdempotencyService, feed *service.ChangeFeed, projectClient *project.ProjectClient) *DocumentHandler {
 	return &DocumentHandler{
-		docService: docService,
+		docService:    docService,
+		idempotency:   idempotency,
+		feed:          feed,
+		projectClient: projectClient,
 	}
 }
 
 func (h *DocumentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Пользовательские маршруты проекта имеют приоритет над CRUD коллекций.
+	// Задержка и сбои проекта применяются к ним так же, как к коллекциям
+	if endpoint, params := h.matchEndpoint(r); endpoint != nil {
+		handle := func(w http.ResponseWriter, r *http.Request) { h.serveEndpoint(w, r, endpoint, params) }
+		r, ok := h.simulateDelay(w, r, "")
+		if !ok {
+			return
+		}
+		if h.injectFault(w, r, "", handle) {
+			return
+		}
+		handle(w, r)
+		return
+	}
+
 	pathParts := strings.Split(r.URL.Path, "/")
 
-	// После middleware путь будет: /{collection} или /{collection}/{id}
+	// После middleware путь будет: /{collection}, /{collection}/{id} или /{collection}/{id}/{child}
+	var handle http.HandlerFunc
 	if len(pathParts) == 2 && pathParts[1] != "" {
 		// /{collection}
-		h.HandleCollection(w, r)
+		handle = h.HandleCollection
+	} else if len(pathParts) == 3 && pathParts[1] != "" && strings.HasPrefix(pathParts[2], "_") {
+		// /{collection}/_{action} - служебные операции коллекции (ID документов не начинаются с "_")
+		handle = func(w http.ResponseWriter, r *http.Request) { h.HandleCollectionAction(w, r, pathParts[2]) }
 	} else if len(pathParts) == 3 && pathParts[1] != "" && pathParts[2] != "" {
 		// /{collection}/{id}
-		h.HandleDocument(w, r)
+		handle = h.HandleDocument
+	} else if len(pathParts) == 4 && pathParts[1] != "" && pathParts[2] != "" && strings.HasPrefix(pathParts[3], "_") {
+		// /{collection}/{id}/_{action} - служебные операции документа
+		handle = func(w http.ResponseWriter, r *http.Request) { h.HandleDocumentAction(w, r, pathParts[3]) }
+	} else if len(pathParts) == 4 && pathParts[1] != "" && pathParts[2] != "" && pathParts[3] != "" {
+		// /{collection}/{id}/{child}
+		handle = h.HandleNestedCollection
 	} else {
 		writeErrorJson(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+	// Служебные коллекции data service недоступны через API
+	if err := models.ValidateCollectionName(pathParts[1]); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	r, ok := h.simulateDelay(w, r, pathParts[1])
+	if !ok {
+		return
+	}
+	if h.injectFault(w, r, pathParts[1], handle) {
+		return
 	}
+
+	var documentID string
+	if len(pathParts) > 2 && !strings.HasPrefix(pathParts[2], "_") {
+		documentID = pathParts[2]
+	}
+	if h.applyRules(w, r, pathParts[1], documentID) {
+		return
+	}
+
+	// Idempotency-Key учитывается только при создании документа POST /{collection}
+	if r.Method == http.MethodPost && len(pathParts) == 2 && r.Header.Get("Idempotency-Key") != "" {
+		h.serveIdempotent(w, r, handle)
+		return
+	}
+	handle(w, r)
+}
+
+// simulateDelay выдерживает задержку ответа: ?_delay, иначе настройка коллекции, иначе проекта.
+// Для пользовательских маршрутов collectionName пуст. Загруженная схема сохраняется
+// в контексте запроса, чтобы обработчики не запрашивали ее повторно
+func (h *DocumentHandler) simulateDelay(w http.ResponseWriter, r *http.Request, collectionName string) (*http.Request, bool) {
+	project, err := context.GetProjectInfo(r.Context())
+	if err != nil {
+		writeErrorJson(w, http.StatusUnauthorized, "Project not authenticated")
+		return r, false
+	}
+
+	config := h.projectDelay(project.ID)
+	if collectionName != "" {
+		schema, err := h.getCollectionSchema(w, r, project, collectionName)
+		if err != nil {
+			return r, false
+		}
+		r = r.WithContext(context.WithCollectionSchema(r.Context(), collectionName, schema))
+		if schema != nil && schema.Config.Delay != nil {
+			config = schema.Config.Delay
+		}
+	}
+
+	delay, err := service.ResponseDelay(config, r.URL.Query().Get("_delay"))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return r, false
+	}
+	if delay == 0 {
+		return r, true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return r, true
+	case <-r.Context().Done():
+		// Клиент не дождался ответа
+		return r, false
+	}
+}
+
+// projectDelay возвращает задержку ответов из настроек проекта. Недоступность
+// project сервиса не должна ломать запросы, поэтому ошибка означает отсутствие задержки
+func (h *DocumentHandler) projectDelay(projectID int64) *models.DelayConfig {
+	settings, err := h.projectClient.GetSettings(projectID)
+	if err != nil {
+		slog.Warn("failed to get project settings, serving without project delay",
+			slog.Int64("project_id", projectID),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	return settings.Delay
 }
 
 // HandleCollection godoc
@@ -69,13 +190,28 @@ func (h *DocumentHandler) HandleCollection(w http.ResponseWriter, r *http.Reques
 // @Description Get all documents from a collection with pagination
 // @Tags documents
 // @Produce json
+// @Produce text/csv
 // @Param api_key path string true "API Key"
 // @Param collection path string true "Collection Name"
+// @Param format query string false "Response format: csv exports documents as CSV (nested fields as address.city columns), same as Accept: text/csv" Enums(json, csv)
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
-// @Param sort query string false "Sort field" default(created_at)
-// @Param order query string false "Sort order" default(desc)
-// @Success 200 {object} model.DocumentsResponse
+// @Param page query int false "Page number (alternative to limit/offset)"
+// @Param per_page query int false "Page size (alternative to limit/offset)" default(10)
+// @Param cursor query string false "Cursor pagination: empty for the first page, then next_cursor from the previous response"
+// @Param sort query string false "Sort fields, comma separated, '-' prefix for descending: -price,name" default(-created_at)
+// @Param order query string false "Default sort order for keys without prefix" Enums(asc, desc)
+// @Param q query string false "Full-text search across string fields"
+// @Param _expand query string false "Inline documents referenced by reference fields: _expand=user (field userId or user)"
+// @Param _embed query string false "Inline documents of another collection referencing each document: _embed=comments"
+// @Param _delay query string false "Simulated response delay in ms or a min-max range, overrides collection and project config: _delay=300"
+// @Param X-Mock-Fail header string false "Force a simulated failure: error status (503), drop or truncate"
+// @Param field query string false "Filter by data field: ?field=value, ?field_ne=, _gt, _gte, _lt, _lte, _in (comma separated), _like (regex), _exists (true/false)"
+// @Param as_of query string false "Collection state at a past moment from the change history (RFC 3339 or YYYY-MM-DD), only with pagination"
+// @Success 200 {array} map[string]interface{} "Documents (or {data, meta} if collection config has envelope)"
+// @Header 200 {integer} X-Total-Count "Total number of matching documents"
+// @Header 200 {string} Link "RFC 5988 pagination links: first, prev, next, last"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page (cursor mode)"
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -84,9 +220,35 @@ func (h *DocumentHandler) GetDocuments(w http.ResponseWriter, r *http.Request, p
 	// Парсим query parameters
 	opts := parseQueryOptions(r)
 
-	response, err := h.docService.GetDocuments(project.ID, collectionName, opts)
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
 	if err != nil {
-		writeErrorJson(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var response *model.DocumentsResponse
+	if raw := r.URL.Query().Get("as_of"); raw != "" {
+		// Состояние коллекции в прошлом восстанавливается по истории изменений
+		var asOf time.Time
+		if asOf, err = service.ParseAsOf(raw); err == nil {
+			response, err = h.docService.GetDocumentsAsOf(project.ID, collectionName, asOf, opts)
+		}
+	} else {
+		response, err = h.docService.GetDocuments(project.ID, collectionName, schema, opts)
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeDocuments(w, r, project, collectionName, schema, opts, response)
+}
+
+// writeDocuments записывает страницу документов: связанные документы (?_expand, ?_embed),
+// заголовки пагинации и тело в виде массива или {data, meta}
+func (h *DocumentHandler) writeDocuments(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName string, schema *models.Collection, opts model.QueryOptions, response *model.DocumentsResponse) {
+	expand, embed := parseRelations(r)
+	if err := h.docService.IncludeRelated(project.ID, collectionName, schema, response.Documents, expand, embed, h.schemaLoader(project)); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -96,21 +258,36 @@ func (h *DocumentHandler) GetDocuments(w http.ResponseWriter, r *http.Request, p
 		cleanDocs[i] = doc.ToClean()
 	}
 
+	setPaginationHeaders(w, r, opts, response)
+
+	if wantsCSV(r) {
+		writeCSV(w, collectionName, schema, cleanDocs)
+		return
+	}
+
+	if schema != nil && schema.Config.Envelope {
+		writeEnvelopeJson(w, http.StatusOK, cleanDocs, paginationMeta(opts, response))
+		return
+	}
+
 	writeOrderedJson(w, http.StatusOK, cleanDocs)
 }
 
 // CreateDocument godoc
 // @Summary Create document
-// @Description Create new document in collection
+// @Description Create new document in collection. If the collection has a schema, the document is validated against it (422 with the list of violations)
 // @Tags documents
 // @Accept json
 // @Produce json
 // @Param api_key path string true "API Key"
 // @Param collection path string true "Collection Name"
 // @Param request body map[string]interface{} true "Document data"
+// @Param Idempotency-Key header string false "Key for safe retries: a repeated request returns the original response (status and headers only if the body was too large to store)"
 // @Success 201 {object} model.DocumentResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string "Unique field value already exists or request with the same Idempotency-Key is in progress"
+// @Failure 422 {object} map[string]interface{} "Schema violations or Idempotency-Key reused with a different request"
 // @Router /{api_key}/{collection} [post]
 func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName string) {
 	var data map[string]interface{}
@@ -124,25 +301,273 @@ func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	document, err := h.docService.CreateDocument(project.ID, collectionName, data)
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
+	if err != nil {
+		return
+	}
+
+	document, err := h.docService.CreateDocument(project.ID, collectionName, schema, data, changeSource(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	setDocumentValidators(w, document)
+	// Чистый формат для публичного API
+	writeOrderedJson(w, http.StatusCreated, document.ToClean())
+}
+
+// HandleCollectionAction выбирает обработчик служебной операции коллекции
+func (h *DocumentHandler) HandleCollectionAction(w http.ResponseWriter, r *http.Request, action string) {
+	switch action {
+	case "_bulk":
+		h.HandleBulk(w, r)
+	case "_generate":
+		h.HandleGenerate(w, r)
+	case "_import":
+		h.HandleImport(w, r)
+	case "_export":
+		h.HandleExport(w, r)
+	case "_stream":
+		h.HandleStream(w, r)
+	default:
+		writeErrorJson(w, http.StatusNotFound, "Endpoint not found")
+	}
+}
+
+// HandleBulk godoc
+// @Summary Bulk write documents
+// @Description Execute an array of create/update/delete operations in one request. Update replaces document data like PUT. Invalid operations fail individually (422 with violations for schema errors); the documents limit is checked once for all creates
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Collection Name"
+// @Param request body []model.BulkOperation true "Operations"
+// @Success 200 {object} model.BulkResponse "Per-operation results with HTTP-like status codes"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 405 {object} map[string]string
+// @Router /{api_key}/{collection}/_bulk [post]
+func (h *DocumentHandler) HandleBulk(w http.ResponseWriter, r *http.Request) {
+	project, collectionName, err := extractProjectAndCollection(w, r)
+	if err != nil {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var ops []model.BulkOperation
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid request body: expected an array of operations")
+		return
+	}
+
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
+	if err != nil {
+		return
+	}
+
+	response, err := h.docService.BulkWrite(project.ID, collectionName, schema, ops, changeSource(r))
 	if err != nil {
 		writeErrorJson(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	writeSuccessJson(w, http.StatusOK, response)
+}
+
+// HandleGenerate godoc
+// @Summary Generate collection documents
+// @Description Generate documents by the collection schema and save them. Unique fields get values not used in the collection yet, reference fields get ids of existing documents of the target collection
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Collection Name"
+// @Param request body model.GenerateRequest false "Count (defaults to collection config) and seed"
+// @Success 201 {object} GenerateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 405 {object} map[string]string
+// @Router /{api_key}/{collection}/_generate [post]
+func (h *DocumentHandler) HandleGenerate(w http.ResponseWriter, r *http.Request) {
+	project, collectionName, err := extractProjectAndCollection(w, r)
+	if err != nil {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.GenerateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
+	if err != nil {
+		return
+	}
+	if schema == nil {
+		writeErrorJson(w, http.StatusNotFound, "Collection schema not found")
+		return
+	}
+
+	documents, err := h.docService.GenerateDocuments(project.ID, schema, &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	cleanDocs := make([]map[string]interface{}, len(documents))
+	for i, doc := range documents {
+		cleanDocs[i] = doc.ToClean()
+	}
+
+	writeOrderedJson(w, http.StatusCreated, GenerateResponse{
+		Documents: cleanDocs,
+		Count:     len(cleanDocs),
+	})
+}
+
+// HandleNestedCollection godoc
+// @Summary Handle nested collection
+// @Description List or create documents of a child collection that reference the parent document: /users/1/posts. The foreign key is the reference field of the child schema pointing to the parent collection, or userId by convention
+// @Tags documents
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Parent Collection Name"
+// @Param id path string true "Parent Document ID"
+// @Param child path string true "Child Collection Name"
+// @Router /{api_key}/{collection}/{id}/{child} [get]
+// @Router /{api_key}/{collection}/{id}/{child} [post]
+func (h *DocumentHandler) HandleNestedCollection(w http.ResponseWriter, r *http.Request) {
+	project, collectionName, err := extractProjectAndCollection(w, r)
+	if err != nil {
+		return
+	}
+	childCollection := extractChildCollection(r)
+	if err := models.ValidateCollectionName(childCollection); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	parent, err := h.docService.GetDocument(project.ID, collectionName, extractDocumentID(r))
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if parent == nil {
+		writeErrorJson(w, http.StatusNotFound, "Document not found")
+		return
+	}
+
+	childSchema, err := h.getCollectionSchema(w, r, project, childCollection)
+	if err != nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetChildDocuments(w, r, project, collectionName, parent, childCollection, childSchema)
+	case http.MethodPost:
+		h.CreateChildDocument(w, r, project, collectionName, parent, childCollection, childSchema)
+	}
+}
+
+// GetChildDocuments godoc
+// @Summary Get child documents
+// @Description Get documents of a child collection that reference the parent document. Supports the same pagination, sorting, search and filter parameters as the collection list
+// @Tags documents
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Parent Collection Name"
+// @Param id path string true "Parent Document ID"
+// @Param child path string true "Child Collection Name"
+// @Success 200 {array} map[string]interface{} "Documents (or {data, meta} if child collection config has envelope)"
+// @Header 200 {integer} X-Total-Count "Total number of matching documents"
+// @Header 200 {string} Link "RFC 5988 pagination links: first, prev, next, last"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /{api_key}/{collection}/{id}/{child} [get]
+func (h *DocumentHandler) GetChildDocuments(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName string, parent *model.MockDocument, childCollection string, childSchema *models.Collection) {
+	opts := parseQueryOptions(r)
+
+	response, err := h.docService.GetChildDocuments(project.ID, collectionName, parent, childCollection, childSchema, opts)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeDocuments(w, r, project, childCollection, childSchema, opts, response)
+}
+
+// CreateChildDocument godoc
+// @Summary Create child document
+// @Description Create document in a child collection with the foreign key set to the parent document
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Parent Collection Name"
+// @Param id path string true "Parent Document ID"
+// @Param child path string true "Child Collection Name"
+// @Param request body map[string]interface{} true "Document data"
+// @Success 201 {object} model.DocumentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Unique field value already exists"
+// @Failure 422 {object} map[string]interface{} "Schema violations"
+// @Router /{api_key}/{collection}/{id}/{child} [post]
+func (h *DocumentHandler) CreateChildDocument(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName string, parent *model.MockDocument, childCollection string, childSchema *models.Collection) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(data) == 0 {
+		writeErrorJson(w, http.StatusBadRequest, "Document data is required")
+		return
+	}
+
+	document, err := h.docService.CreateChildDocument(project.ID, collectionName, parent, childCollection, childSchema, data, changeSource(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	setDocumentValidators(w, document)
 	// Чистый формат для публичного API
 	writeOrderedJson(w, http.StatusCreated, document.ToClean())
 }
 
 // HandleDocument godoc
 // @Summary Handle document operations
-// @Description Handle GET, PUT and DELETE operations for a document
+// @Description Handle GET, PUT, PATCH and DELETE operations for a document
 // @Tags documents
 // @Param api_key path string true "API Key"
 // @Param collection path string true "Collection Name"
 // @Param id path string true "Document ID"
 // @Router /{api_key}/{collection}/{id} [get]
 // @Router /{api_key}/{collection}/{id} [put]
+// @Router /{api_key}/{collection}/{id} [patch]
 // @Router /{api_key}/{collection}/{id} [delete]
 func (h *DocumentHandler) HandleDocument(w http.ResponseWriter, r *http.Request) {
 	project, collectionName, err := extractProjectAndCollection(w, r)
@@ -161,6 +586,8 @@ func (h *DocumentHandler) HandleDocument(w http.ResponseWriter, r *http.Request)
 		h.GetDocument(w, r, project, collectionName, documentID)
 	case http.MethodPut:
 		h.UpdateDocument(w, r, project, collectionName, documentID)
+	case http.MethodPatch:
+		h.PatchDocument(w, r, project, collectionName, documentID)
 	case http.MethodDelete:
 		h.DeleteDocument(w, r, project, collectionName, documentID)
 	}
@@ -174,13 +601,34 @@ func (h *DocumentHandler) HandleDocument(w http.ResponseWriter, r *http.Request)
 // @Param api_key path string true "API Key"
 // @Param collection path string true "Collection Name"
 // @Param id path string true "Document ID"
+// @Param _expand query string false "Inline documents referenced by reference fields: _expand=user (field userId or user)"
+// @Param _embed query string false "Inline documents of another collection referencing the document: _embed=comments"
+// @Param _delay query string false "Simulated response delay in ms or a min-max range, overrides collection and project config: _delay=300"
+// @Param X-Mock-Fail header string false "Force a simulated failure: error status (503), drop or truncate"
+// @Param If-None-Match header string false "ETag from a previous response: 304 if the document has not changed"
+// @Param If-Modified-Since header string false "304 if the document has not changed since the date"
+// @Param as_of query string false "Document state at a past moment from the change history (RFC 3339 or YYYY-MM-DD)"
 // @Success 200 {object} model.DocumentResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Document version tag"
+// @Header 200 {string} Last-Modified "Document modification time"
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /{api_key}/{collection}/{id} [get]
 func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName, documentID string) {
-	document, err := h.docService.GetDocument(project.ID, collectionName, documentID)
+	var document *model.MockDocument
+	var err error
+	if raw := r.URL.Query().Get("as_of"); raw != "" {
+		asOf, parseErr := service.ParseAsOf(raw)
+		if parseErr != nil {
+			writeServiceError(w, parseErr, http.StatusBadRequest)
+			return
+		}
+		document, err = h.docService.GetDocumentAsOf(project.ID, collectionName, documentID, asOf)
+	} else {
+		document, err = h.docService.GetDocument(project.ID, collectionName, documentID)
+	}
 	if err != nil {
 		writeErrorJson(w, http.StatusInternalServerError, err.Error())
 		return
@@ -191,13 +639,33 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request, pr
 		return
 	}
 
+	expand, embed := parseRelations(r)
+	if len(expand) == 0 && len(embed) == 0 {
+		// Валидаторы описывают сам документ; со связанными документами ответ от них не зависит
+		setDocumentValidators(w, document)
+		if notModified(r, document) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else {
+		schema, err := h.getCollectionSchema(w, r, project, collectionName)
+		if err != nil {
+			return
+		}
+		docs := []*model.MockDocument{document}
+		if err := h.docService.IncludeRelated(project.ID, collectionName, schema, docs, expand, embed, h.schemaLoader(project)); err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Чистый формат для публичного API
 	writeOrderedJson(w, http.StatusOK, document.ToClean())
 }
 
 // UpdateDocument godoc
 // @Summary Update document
-// @Description Update existing document
+// @Description Update existing document. If the collection has a schema, the document is validated against it (422 with the list of violations)
 // @Tags documents
 // @Accept json
 // @Produce json
@@ -205,10 +673,15 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request, pr
 // @Param collection path string true "Collection Name"
 // @Param id path string true "Document ID"
 // @Param request body map[string]interface{} true "Document data"
+// @Param If-Match header string false "ETag of the document version being replaced"
 // @Success 200 {object} model.DocumentResponse
+// @Header 200 {string} ETag "New document version tag"
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Unique field value already exists"
+// @Failure 412 {object} map[string]string "If-Match does not match the current document version"
+// @Failure 422 {object} map[string]interface{} "Schema violations"
 // @Router /{api_key}/{collection}/{id} [put]
 func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName, documentID string) {
 	var data map[string]interface{}
@@ -217,9 +690,14 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	document, err := h.docService.UpdateDocument(project.ID, collectionName, documentID, data)
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
 	if err != nil {
-		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	document, err := h.docService.UpdateDocument(project.ID, collectionName, documentID, schema, data, r.Header.Get("If-Match"), changeSource(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
 		return
 	}
 
@@ -228,6 +706,76 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	setDocumentValidators(w, document)
+
+	// Чистый формат для публичного API
+	writeOrderedJson(w, http.StatusOK, document.ToClean())
+}
+
+// PatchDocument godoc
+// @Summary Patch document
+// @Description Partially update document with JSON Merge Patch (application/merge-patch+json, RFC 7396) or JSON Patch (application/json-patch+json, RFC 6902). Plain application/json is treated as merge patch. Document id is preserved
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Collection Name"
+// @Param id path string true "Document ID"
+// @Param request body map[string]interface{} true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string false "ETag of the document version being patched"
+// @Success 200 {object} model.DocumentResponse
+// @Header 200 {string} ETag "New document version tag"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string "If-Match does not match the current document version"
+// @Failure 415 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /{api_key}/{collection}/{id} [patch]
+func (h *DocumentHandler) PatchDocument(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName, documentID string) {
+	var patch service.DocumentPatch
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json-patch+json":
+		var operations []model.PatchOperation
+		if err := json.NewDecoder(r.Body).Decode(&operations); err != nil {
+			writeErrorJson(w, http.StatusBadRequest, "Invalid JSON Patch document")
+			return
+		}
+		patch = service.JSONPatch(operations)
+	case "", "application/json", "application/merge-patch+json":
+		var mergePatch map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&mergePatch); err != nil {
+			writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		patch = service.MergePatch(mergePatch)
+	default:
+		w.Header().Set("Accept-Patch", "application/merge-patch+json, application/json-patch+json")
+		writeErrorJson(w, http.StatusUnsupportedMediaType, "Unsupported patch format")
+		return
+	}
+
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
+	if err != nil {
+		return
+	}
+
+	document, err := h.docService.PatchDocument(project.ID, collectionName, documentID, schema, patch, r.Header.Get("If-Match"), changeSource(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if document == nil {
+		writeErrorJson(w, http.StatusNotFound, "Document not found")
+		return
+	}
+
+	setDocumentValidators(w, document)
+
 	// Чистый формат для публичного API
 	writeOrderedJson(w, http.StatusOK, document.ToClean())
 }
@@ -240,14 +788,16 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request,
 // @Param api_key path string true "API Key"
 // @Param collection path string true "Collection Name"
 // @Param id path string true "Document ID"
+// @Param If-Match header string false "ETag of the document version being deleted"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "If-Match does not match the current document version"
 // @Router /{api_key}/{collection}/{id} [delete]
 func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName, documentID string) {
-	if err := h.docService.DeleteDocument(project.ID, collectionName, documentID); err != nil {
-		writeErrorJson(w, http.StatusBadRequest, err.Error())
+	if err := h.docService.DeleteDocument(project.ID, collectionName, documentID, r.Header.Get("If-Match"), changeSource(r)); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
 		return
 	}
 
@@ -266,7 +816,7 @@ func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request,
 // @Failure 401 {object} map[string]string
 // @Router /{api_key}/{collection} [delete]
 func (h *DocumentHandler) FlushCollection(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName string) {
-	deletedCount, err := h.docService.FlushCollection(project.ID, collectionName)
+	deletedCount, err := h.docService.FlushCollection(project.ID, collectionName, changeSource(r))
 	if err != nil {
 		writeErrorJson(w, http.StatusBadRequest, err.Error())
 		return
@@ -278,6 +828,55 @@ func (h *DocumentHandler) FlushCollection(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// setDocumentValidators выставляет заголовки ETag и Last-Modified документа
+func setDocumentValidators(w http.ResponseWriter, document *model.MockDocument) {
+	w.Header().Set("ETag", document.ETag())
+	w.Header().Set("Last-Modified", document.UpdatedAt.UTC().Format(http.TimeFormat))
+}
+
+// notModified проверяет условия If-None-Match и If-Modified-Since (учитывается,
+// только если If-None-Match не передан)
+func notModified(r *http.Request, document *model.MockDocument) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return service.MatchesETag(ifNoneMatch, document.ETag(), true)
+	}
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		return !document.UpdatedAt.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// getCollectionSchema загружает схему коллекции из Project Service (или берет уже
+// загруженную для запроса). Для коллекций без схемы (schema-less режим) возвращает nil
+func (h *DocumentHandler) getCollectionSchema(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName string) (*models.Collection, error) {
+	if schema, ok := context.GetCollectionSchema(r.Context(), collectionName); ok {
+		return schema, nil
+	}
+
+	schema, err := h.projectClient.GetCollection(project.ID, collectionName)
+	if err != nil {
+		slog.Error("failed to get collection schema",
+			slog.Int64("project_id", project.ID),
+			slog.String("collection", collectionName),
+			slog.String("error", err.Error()),
+		)
+		writeErrorJson(w, http.StatusBadGateway, "Failed to load collection schema")
+		return nil, err
+	}
+	return schema, nil
+}
+
+// schemaLoader загружает схемы других коллекций проекта (для ?_embed)
+func (h *DocumentHandler) schemaLoader(project *project.ProjectInfo) service.SchemaLoader {
+	return func(collectionName string) (*models.Collection, error) {
+		schema, err := h.projectClient.GetCollection(project.ID, collectionName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load collection schema %s: %v", collectionName, err)
+		}
+		return schema, nil
+	}
+}
+
 func extractProjectAndCollection(w http.ResponseWriter, r *http.Request) (*project.ProjectInfo, string, error) {
 	project, err := context.GetProjectInfo(r.Context())
 	if err != nil {
@@ -285,21 +884,14 @@ func extractProjectAndCollection(w http.ResponseWriter, r *http.Request) (*proje
 		return nil, "", err
 	}
 
+	// Gateway передает путь без API ключа: /{collection}[/{id}[/{child}]]
 	pathParts := strings.Split(r.URL.Path, "/")
-	
-	// Определяем, откуда пришел запрос - от gateway или напрямую
-	var collectionName string
-	if len(pathParts) == 2 {
-		// /{collection} - от gateway
-		collectionName = pathParts[1]
-	} else if len(pathParts) >= 3 {
-		// /{api_key}/{collection} или /{api_key}/{collection}/... - напрямую
-		collectionName = pathParts[2]
-	} else {
+	if len(pathParts) < 2 {
 		writeErrorJson(w, http.StatusBadRequest, "Invalid collection name")
 		return nil, "", fmt.Errorf("invalid collection name")
 	}
 
+	collectionName := pathParts[1]
 	if collectionName == "" {
 		writeErrorJson(w, http.StatusBadRequest, "Collection name is required")
 		return nil, "", fmt.Errorf("collection name is required")
@@ -309,14 +901,18 @@ func extractProjectAndCollection(w http.ResponseWriter, r *http.Request) (*proje
 }
 
 func extractDocumentID(r *http.Request) string {
+	// /{collection}/{id}[/...]
 	pathParts := strings.Split(r.URL.Path, "/")
-	
-	// Определяем, откуда пришел запрос - от gateway или напрямую
-	if len(pathParts) == 3 {
-		// /{collection}/{id} - от gateway
+	if len(pathParts) >= 3 {
 		return pathParts[2]
-	} else if len(pathParts) >= 4 {
-		// /{api_key}/{collection}/{id} - напрямую
+	}
+	return ""
+}
+
+func extractChildCollection(r *http.Request) string {
+	// /{collection}/{id}/{child}
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) >= 4 {
 		return pathParts[3]
 	}
 	return ""
@@ -337,6 +933,31 @@ func parseQueryOptions(r *http.Request) model.QueryOptions {
 		}
 	}
 
+	// page/per_page - альтернатива limit/offset
+	page := parsePositiveInt(r.URL.Query().Get("page"))
+	perPage := parsePositiveInt(r.URL.Query().Get("per_page"))
+	if (page != nil || perPage != nil) && opts.Limit == nil && opts.Offset == nil {
+		if page == nil {
+			page = int64Ptr(1)
+		}
+		if perPage == nil {
+			perPage = int64Ptr(defaultPerPage)
+		}
+		opts.Page, opts.PerPage = page, perPage
+		opts.Limit = int64Ptr(*perPage)
+		opts.Offset = int64Ptr((*page - 1) * *perPage)
+	}
+
+	// cursor - курсорная пагинация (пустое значение - первая страница)
+	if values, ok := r.URL.Query()["cursor"]; ok {
+		cursor := values[0]
+		opts.Cursor = &cursor
+		opts.Offset, opts.Page, opts.PerPage = nil, nil, nil
+		if opts.Limit == nil {
+			opts.Limit = int64Ptr(defaultPerPage)
+		}
+	}
+
 	if sort := r.URL.Query().Get("sort"); sort != "" {
 		opts.Sort = sort
 	}
@@ -345,15 +966,110 @@ func parseQueryOptions(r *http.Request) model.QueryOptions {
 		opts.Order = order
 	}
 
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		opts.Search = q
+	}
+
+	opts.Filters = parseFieldFilters(r)
+
 	return opts
 }
 
+// parseRelations возвращает связи из ?_expand и ?_embed
+// (параметр можно повторять или перечислять связи через запятую)
+func parseRelations(r *http.Request) (expand, embed []string) {
+	return parseList(r.URL.Query()["_expand"]), parseList(r.URL.Query()["_embed"])
+}
+
+func parseList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// filterOperators поддерживаемые суффиксы операторов фильтрации
+var filterOperators = map[string]bool{
+	model.FilterNe:     true,
+	model.FilterGt:     true,
+	model.FilterGte:    true,
+	model.FilterLt:     true,
+	model.FilterLte:    true,
+	model.FilterIn:     true,
+	model.FilterLike:   true,
+	model.FilterExists: true,
+}
+
+// parseFieldFilters разбирает фильтры вида ?field=value и ?field_{operator}=value
+func parseFieldFilters(r *http.Request) []model.FieldFilter {
+	query := r.URL.Query()
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if key == "" || models.IsReservedQueryParam(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var filters []model.FieldFilter
+	for _, key := range keys {
+		field, operator := key, model.FilterEq
+		if i := strings.LastIndex(key, "_"); i > 0 && filterOperators[key[i+1:]] {
+			field, operator = key[:i], key[i+1:]
+		}
+
+		filters = append(filters, model.FieldFilter{
+			Field:    field,
+			Operator: operator,
+			Raw:      query[key],
+		})
+	}
+
+	return filters
+}
+
 func writeErrorJson(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
+// writeServiceError выбирает HTTP статус по типу ошибки сервиса
+func writeServiceError(w http.ResponseWriter, err error, defaultStatus int) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		writeSuccessJson(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":      err.Error(),
+			"violations": validationErr.Violations,
+		})
+		return
+	}
+
+	status := defaultStatus
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidPatch):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrNotSupported):
+		status = http.StatusNotImplemented
+	}
+	writeErrorJson(w, status, err.Error())
+}
+
 func writeSuccessJson(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -364,21 +1080,14 @@ func writeSuccessJson(w http.ResponseWriter, statusCode int, data interface{}) {
 func writeOrderedJson(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	
+
 	// Для массива или одного документа формируем упорядоченный JSON
 	var buf bytes.Buffer
-	
+
 	switch v := data.(type) {
 	case []map[string]interface{}:
 		// Массив документов
-		buf.WriteString("[")
-		for i, doc := range v {
-			if i > 0 {
-				buf.WriteString(",")
-			}
-			buf.Write(orderDocument(doc))
-		}
-		buf.WriteString("]")
+		buf.Write(orderDocuments(v))
 	case map[string]interface{}:
 		// Один документ
 		buf.Write(orderDocument(v))
@@ -387,10 +1096,44 @@ func writeOrderedJson(w http.ResponseWriter, statusCode int, data interface{}) {
 		json.NewEncoder(w).Encode(data)
 		return
 	}
-	
+
 	w.Write(buf.Bytes())
 }
 
+// writeEnvelopeJson записывает список документов в формате {"data": [...], "meta": {...}}
+func writeEnvelopeJson(w http.ResponseWriter, statusCode int, docs []map[string]interface{}, meta interface{}) {
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"data":`)
+	buf.Write(orderDocuments(docs))
+	buf.WriteString(`,"meta":`)
+	buf.Write(metaJSON)
+	buf.WriteString("}")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(buf.Bytes())
+}
+
+// orderDocuments формирует JSON массив документов с упорядоченными полями
+func orderDocuments(docs []map[string]interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, doc := range docs {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.Write(orderDocument(doc))
+	}
+	buf.WriteString("]")
+	return buf.Bytes()
+}
+
 // orderDocument упорядочивает поля документа (id первым, остальные в алфавитном порядке)
 func orderDocument(doc map[string]interface{}) []byte {
 	// Собираем ключи
@@ -398,7 +1141,7 @@ func orderDocument(doc map[string]interface{}) []byte {
 	for k := range doc {
 		keys = append(keys, k)
 	}
-	
+
 	// Сортируем с приоритетом для id
 	sort.Slice(keys, func(i, j int) bool {
 		if keys[i] == "id" {
@@ -409,7 +1152,7 @@ func orderDocument(doc map[string]interface{}) []byte {
 		}
 		return keys[i] < keys[j]
 	})
-	
+
 	// Строим JSON вручную
 	result := "{"
 	for i, k := range keys {
@@ -421,6 +1164,6 @@ func orderDocument(doc map[string]interface{}) []byte {
 		result += string(valueJSON)
 	}
 	result += "}"
-	
+
 	return []byte(result)
 }
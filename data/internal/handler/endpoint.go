@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/pkg/context"
+	"github.com/go-mockingcode/data/internal/service"
+	"github.com/go-mockingcode/models"
+)
+
+// maxEndpointBody ограничение тела запроса, читаемого для шаблона ответа и условий правил
+const maxEndpointBody = 1 << 20
+
+// matchEndpoint находит пользовательский маршрут проекта, подходящий запросу по методу
+// и пути. Возвращает nil, если запрос нужно обработать как CRUD коллекции
+func (h *DocumentHandler) matchEndpoint(r *http.Request) (*models.Endpoint, map[string]string) {
+	project, err := context.GetProjectInfo(r.Context())
+	if err != nil {
+		return nil, nil
+	}
+
+	// Недоступность project сервиса не должна ломать CRUD коллекций:
+	// без списка маршрутов запрос обрабатывается как обычный
+	endpoints, err := h.projectClient.GetEndpoints(project.ID)
+	if err != nil {
+		slog.Warn("failed to get custom endpoints, serving as collection",
+			slog.Int64("project_id", project.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, nil
+	}
+
+	return service.MatchEndpoint(endpoints, r.Method, r.URL.Path)
+}
+
+// serveEndpoint отвечает на запрос пользовательским маршрутом проекта
+func (h *DocumentHandler) serveEndpoint(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, params map[string]string) {
+	project, err := context.GetProjectInfo(r.Context())
+	if err != nil {
+		writeErrorJson(w, http.StatusUnauthorized, "Project not authenticated")
+		return
+	}
+
+	// Запрос полностью обслуживается маршрутом, поэтому тело больше лимита отклоняется
+	r.Body = http.MaxBytesReader(w, r.Body, maxEndpointBody)
+	req, err := requestData(r, params, true)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrorJson(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		writeErrorJson(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	if rule := service.MatchRule(endpoint.Rules, req); rule != nil {
+		writeRuleResponse(w, rule)
+		return
+	}
+
+	body, err := h.docService.RenderEndpoint(project.ID, endpoint, req)
+	if err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	for key, value := range endpoint.Headers {
+		w.Header().Set(key, value)
+	}
+	status := endpoint.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// requestData собирает данные запроса для шаблона ответа и условий правил.
+// Тело читается только при withBody и не дальше maxEndpointBody; больше лимита
+// оно в данные не попадает. Тело запроса остается доступным для дальнейшей обработки
+func requestData(r *http.Request, params map[string]string, withBody bool) (service.RequestData, error) {
+	req := service.RequestData{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Params:  params,
+		Query:   make(map[string]string),
+		Headers: make(map[string]string),
+	}
+	for key, values := range r.URL.Query() {
+		req.Query[key] = values[0]
+	}
+	for key, values := range r.Header {
+		req.Headers[key] = values[0]
+	}
+
+	if !withBody {
+		return req, nil
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(r.Body, maxEndpointBody+1))
+	if err != nil {
+		return req, err
+	}
+	// Прочитанная часть возвращается перед непрочитанным остатком тела
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(raw), r.Body), r.Body}
+	if len(raw) > maxEndpointBody {
+		return req, nil
+	}
+	if len(raw) > 0 {
+		var body any
+		if err := json.Unmarshal(raw, &body); err != nil {
+			body = string(raw)
+		}
+		req.Body = body
+	}
+	return req, nil
+}
+
+// applyRules отвечает на запрос к коллекции условным ответом из ее настроек, если
+// одно из правил подходит. Возвращает false, если запрос обрабатывается как обычно
+func (h *DocumentHandler) applyRules(w http.ResponseWriter, r *http.Request, collectionName, documentID string) bool {
+	schema, _ := context.GetCollectionSchema(r.Context(), collectionName)
+	if schema == nil || len(schema.Config.Rules) == 0 {
+		return false
+	}
+
+	params := map[string]string{}
+	if documentID != "" {
+		params["id"] = documentID
+	}
+	// Тело импорта и других не-JSON запросов не читается: условия по body к ним не применяются
+	req, err := requestData(r, params, isJSONBody(r))
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Failed to read request body")
+		return true
+	}
+
+	rule := service.MatchRule(schema.Config.Rules, req)
+	if rule == nil {
+		return false
+	}
+	writeRuleResponse(w, rule)
+	return true
+}
+
+// isJSONBody проверяет, что тело запроса в JSON (без Content-Type тело считается JSON)
+func isJSONBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// writeRuleResponse записывает условный ответ правила
+func writeRuleResponse(w http.ResponseWriter, rule *models.ResponseRule) {
+	status := rule.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	for key, value := range rule.Headers {
+		w.Header().Set(key, value)
+	}
+	if rule.Body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	writeSuccessJson(w, status, rule.Body)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/pkg/context"
+	"github.com/go-mockingcode/data/internal/service"
+	"github.com/go-mockingcode/models"
+)
+
+// FaultHeader заголовок ответа с видом имитируемого сбоя
+const FaultHeader = "X-Mock-Fault"
+
+// injectFault имитирует сбой по правилам коллекции или заголовку X-Mock-Fail.
+// Возвращает true, если ответ уже сформирован (или соединение разорвано)
+func (h *DocumentHandler) injectFault(w http.ResponseWriter, r *http.Request, collectionName string, handle http.HandlerFunc) bool {
+	var rules []models.FaultRule
+	if schema, _ := context.GetCollectionSchema(r.Context(), collectionName); schema != nil {
+		rules = schema.Config.Faults
+	}
+
+	fault, err := service.PickFault(rules, r.Method, r.Header.Get("X-Mock-Fail"))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return true
+	}
+	if fault == nil {
+		return false
+	}
+	// Потоковые ответы не буферизуются: truncatingWriter не поддерживает Flush и Hijack
+	if fault.Type == models.FaultTruncate && streamingActions[collectionAction(r)] {
+		return false
+	}
+
+	slog.Debug("injecting mock fault",
+		slog.String("collection", collectionName),
+		slog.String("type", fault.Type),
+		slog.Int("status", fault.Status),
+	)
+
+	w.Header().Set(FaultHeader, fault.Type)
+	switch fault.Type {
+	case models.FaultDrop:
+		// Соединение закрывается, не отправив ни статуса, ни заголовков.
+		// Gateway, получив обрыв вместо ответа, так же разрывает соединение с клиентом
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			// HTTP/2 не позволяет перехватить соединение: поток сбрасывается
+			panic(http.ErrAbortHandler)
+		}
+		conn.Close()
+
+	case models.FaultTruncate:
+		// Запрос обрабатывается как обычно (изменения данных сохраняются),
+		// но клиент получает только половину тела
+		tw := &truncatingWriter{header: http.Header{}, status: http.StatusOK}
+		handle(tw, r)
+
+		for key, values := range tw.header {
+			w.Header()[key] = values
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(tw.body.Len()))
+		w.WriteHeader(tw.status)
+		w.Write(tw.body.Bytes()[:tw.body.Len()/2])
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		panic(http.ErrAbortHandler)
+
+	default:
+		for key, value := range fault.Headers {
+			w.Header().Set(key, value)
+		}
+		writeSuccessJson(w, fault.Status, fault.Body)
+	}
+	return true
+}
+
+// streamingActions служебные операции коллекции, ответ которых передается потоком
+var streamingActions = map[string]bool{
+	"_stream": true,
+	"_export": true,
+}
+
+// collectionAction возвращает служебную операцию коллекции из пути /{collection}/_{action}
+func collectionAction(r *http.Request) string {
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) == 3 && strings.HasPrefix(pathParts[2], "_") {
+		return pathParts[2]
+	}
+	return ""
+}
+
+// truncatingWriter накапливает ответ обработчика, чтобы отдать его не полностью
+type truncatingWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (t *truncatingWriter) Header() http.Header { return t.header }
+
+func (t *truncatingWriter) Write(p []byte) (int, error) { return t.body.Write(p) }
+
+func (t *truncatingWriter) WriteHeader(status int) { t.status = status }
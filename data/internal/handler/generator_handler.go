@@ -8,14 +8,10 @@ import (
 	"github.com/go-mockingcode/models"
 )
 
-type GeneratorHandler struct {
-	generator *service.DataGenerator
-}
+type GeneratorHandler struct{}
 
 func NewGeneratorHandler() *GeneratorHandler {
-	return &GeneratorHandler{
-		generator: service.NewDataGenerator(0), // random seed
-	}
+	return &GeneratorHandler{}
 }
 
 // GenerateRequest запрос на генерацию данных
@@ -62,13 +58,18 @@ func (h *GeneratorHandler) HandleGenerate(w http.ResponseWriter, r *http.Request
 		req.Count = 10 // default
 	}
 
-	// Устанавливаем seed если указан
+	// Генератор создается на каждый запрос: Faker не потокобезопасен
+	var seed uint64 // 0 - случайный seed
 	if req.Seed != nil {
-		h.generator = service.NewDataGenerator(*req.Seed)
+		seed = *req.Seed
 	}
 
 	// Генерируем данные
-	documents := h.generator.GenerateDocuments(req.Fields, req.Count)
+	documents, err := service.NewDataGenerator(seed).GenerateDocuments(req.Fields, req.Count, service.ExistingData{})
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	response := GenerateResponse{
 		Documents: documents,
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/pkg/context"
+	"github.com/go-mockingcode/data/internal/pkg/project"
+	"github.com/go-mockingcode/data/internal/service"
+)
+
+// maxGraphQLRequestSize максимальный размер тела запроса GraphQL
+const maxGraphQLRequestSize = 1 << 20
+
+type GraphQLHandler struct {
+	docService    *service.DocumentService
+	projectClient *project.ProjectClient
+}
+
+func NewGraphQLHandler(docService *service.DocumentService, projectClient *project.ProjectClient) *GraphQLHandler {
+	return &GraphQLHandler{
+		docService:    docService,
+		projectClient: projectClient,
+	}
+}
+
+// HandleGraphQL godoc
+// @Summary GraphQL API
+// @Description GraphQL API generated from the project collections that have a schema. Each collection gets a type (users -> User) with its fields, reference fields resolved to documents (userId -> user) and reverse relations (User.posts); a list query users(filter, q, sort, limit, offset, cursor) returning { items total limit offset nextCursor }; user(id) and mutations createUser(input), updateUser(id, input) (partial update, null removes a field) and deleteUser(id). Filters mirror the list endpoint: {price_gte: 10, status_in: ["new", "paid"]}. Accepts POST application/json {query, operationName, variables}, POST application/graphql and GET ?query= (queries only). Queries are limited to 16 KiB of text, a depth of 10 and 500 selected fields; list pages hold at most 100 documents. Errors are returned in the errors array with extensions.code: VALIDATION_FAILED (with violations), BAD_USER_INPUT, CONFLICT, NOT_FOUND
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param request body model.GraphQLRequest false "GraphQL request"
+// @Param query query string false "Query for GET requests"
+// @Success 200 {object} map[string]interface{} "data and errors"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 405 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /{api_key}/graphql [get]
+// @Router /{api_key}/graphql [post]
+func (h *GraphQLHandler) HandleGraphQL(w http.ResponseWriter, r *http.Request) {
+	project, err := context.GetProjectInfo(r.Context())
+	if err != nil {
+		writeErrorJson(w, http.StatusUnauthorized, "Project not authenticated")
+		return
+	}
+
+	var req model.GraphQLRequest
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeGraphQLError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+		// GET не должен изменять данные (GraphQL over HTTP)
+		if service.IsGraphQLMutation(req) {
+			w.Header().Set("Allow", http.MethodPost)
+			writeGraphQLError(w, http.StatusMethodNotAllowed, "mutations require POST")
+			return
+		}
+	case http.MethodPost:
+		if err := readGraphQLRequest(r, w, &req); err != nil {
+			status := http.StatusBadRequest
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeGraphQLError(w, status, err.Error())
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeGraphQLError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if req.Query == "" {
+		writeGraphQLError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	collections, err := h.projectClient.GetCollections(project.ID)
+	if err != nil {
+		slog.Error("failed to get collection schemas",
+			slog.Int64("project_id", project.ID),
+			slog.String("error", err.Error()),
+		)
+		writeGraphQLError(w, http.StatusBadGateway, "Failed to load collection schemas")
+		return
+	}
+
+	result := h.docService.ExecuteGraphQL(r.Context(), project.ID, collections, req, changeSource(r))
+	writeSuccessJson(w, http.StatusOK, result)
+}
+
+// readGraphQLRequest читает тело POST: JSON {query, operationName, variables}
+// или текст запроса с Content-Type application/graphql
+func readGraphQLRequest(r *http.Request, w http.ResponseWriter, req *model.GraphQLRequest) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxGraphQLRequestSize)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/graphql" {
+		query, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		req.Query = string(query)
+		return nil
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+		return errors.New("invalid JSON body: expected {query, operationName, variables}")
+	}
+	return nil
+}
+
+// writeGraphQLError отвечает ошибкой в формате GraphQL: {"errors": [{"message": ...}]}
+func writeGraphQLError(w http.ResponseWriter, statusCode int, message string) {
+	writeSuccessJson(w, statusCode, map[string]any{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-mockingcode/data/internal/model"
+)
+
+// ChangeSourceHeader заголовок с источником изменения для истории документов.
+// Веб-интерфейс передает ui, остальные запросы считаются запросами публичного API
+const ChangeSourceHeader = "X-Change-Source"
+
+// changeSource возвращает источник изменения, выполняемого запросом
+func changeSource(r *http.Request) string {
+	if r.Header.Get(ChangeSourceHeader) == model.ChangeSourceUI {
+		return model.ChangeSourceUI
+	}
+	return model.ChangeSourceAPI
+}
+
+// HandleDocumentAction выбирает обработчик служебной операции документа
+func (h *DocumentHandler) HandleDocumentAction(w http.ResponseWriter, r *http.Request, action string) {
+	switch action {
+	case "_history":
+		h.HandleHistory(w, r)
+	default:
+		writeErrorJson(w, http.StatusNotFound, "Endpoint not found")
+	}
+}
+
+// HandleHistory godoc
+// @Summary Get document change history
+// @Description List create/update/delete changes of the document, newest first: state before and after, time and source (api, ui via X-Change-Source header, generator, import). Available for deleted documents too. Changes older than the history retention period are removed
+// @Tags documents
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Collection Name"
+// @Param id path string true "Document ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} model.HistoryResponse
+// @Failure 401 {object} map[string]string
+// @Failure 405 {object} map[string]string
+// @Router /{api_key}/{collection}/{id}/_history [get]
+func (h *DocumentHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	project, collectionName, err := extractProjectAndCollection(w, r)
+	if err != nil {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	changes, err := h.docService.GetHistory(project.ID, collectionName, extractDocumentID(r), parseQueryOptions(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, model.HistoryResponse{
+		Changes: changes,
+		Count:   len(changes),
+	})
+}
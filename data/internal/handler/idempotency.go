@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-mockingcode/data/internal/pkg/context"
+)
+
+// maxIdempotentBody ограничение тела запроса и сохраняемого ответа с Idempotency-Key
+const maxIdempotentBody = 1 << 20
+
+// serveIdempotent выполняет POST-запрос с заголовком Idempotency-Key: успешный ответ
+// сохраняется и при повторе запроса с тем же ключом возвращается без повторного выполнения
+func (h *DocumentHandler) serveIdempotent(w http.ResponseWriter, r *http.Request, handle http.HandlerFunc) {
+	project, err := context.GetProjectInfo(r.Context())
+	if err != nil {
+		writeErrorJson(w, http.StatusUnauthorized, "Project not authenticated")
+		return
+	}
+	key := r.Header.Get("Idempotency-Key")
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxIdempotentBody)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrorJson(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		writeErrorJson(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	record, err := h.idempotency.Begin(project.ID, key, r.Method, r.URL.Path, body)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if record != nil {
+		for name, values := range record.Headers {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		if record.Truncated {
+			// Запрос уже выполнен, но тело ответа не сохранено: повтор возвращает код и заголовки
+			w.Header().Set("Idempotent-Body-Omitted", "true")
+			writeSuccessJson(w, record.Status, map[string]string{
+				"message": "Request already completed, the original response was too large to store",
+			})
+			return
+		}
+		w.WriteHeader(record.Status)
+		w.Write(record.Body)
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		// Ошибки не сохраняются: запрос можно повторить с тем же ключом
+		if err := h.idempotency.Release(project.ID, key); err != nil {
+			slog.Warn("failed to release idempotency key", slog.String("error", err.Error()))
+		}
+	}()
+
+	handle(recorder, r)
+
+	if recorder.status >= 200 && recorder.status < 300 {
+		headers := w.Header().Clone()
+		if recorder.overflow {
+			// Изменение уже выполнено, поэтому ключ не освобождается: сохраняются только код и заголовки
+			slog.Warn("idempotent response too large to save, storing status only", slog.String("path", r.URL.Path))
+			headers.Del("Content-Length")
+		}
+		if err := h.idempotency.Complete(project.ID, key, recorder.status, headers, recorder.body.Bytes(), recorder.overflow); err != nil {
+			slog.Error("failed to save idempotent response", slog.String("error", err.Error()))
+			return
+		}
+		completed = true
+	}
+}
+
+// responseRecorder передает ответ клиенту и запоминает код и тело.
+// Тело больше maxIdempotentBody не сохраняется (overflow)
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if !rec.overflow {
+		if rec.body.Len()+len(p) > maxIdempotentBody {
+			rec.overflow = true
+			rec.body.Reset()
+		} else {
+			rec.body.Write(p)
+		}
+	}
+	return rec.ResponseWriter.Write(p)
+}
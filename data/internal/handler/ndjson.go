@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/pkg/project"
+)
+
+// ndjsonTypes типы содержимого NDJSON
+var ndjsonTypes = map[string]bool{
+	"application/x-ndjson": true,
+	"application/ndjson":   true,
+	"application/jsonl":    true,
+}
+
+// exportFlushEvery через сколько документов экспорт отправляет накопленные данные клиенту
+const exportFlushEvery = 100
+
+// HandleExport godoc
+// @Summary Export collection as NDJSON
+// @Description Stream all documents of the collection as NDJSON (one JSON document per line) without pagination, in creation order by default. The Mongo cursor is read in batches, so large collections are not loaded into memory. Filters, q and sort work like in the list endpoint. The output can be sent back to _import
+// @Tags documents
+// @Produce application/x-ndjson
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Collection Name"
+// @Param sort query string false "Sort fields, comma separated, '-' prefix for descending" default(created_at)
+// @Param q query string false "Full-text search across string fields"
+// @Param field query string false "Filter by data field, same operators as the list endpoint"
+// @Success 200 {string} string "NDJSON documents"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 405 {object} map[string]string
+// @Router /{api_key}/{collection}/_export [get]
+func (h *DocumentHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	project, collectionName, err := extractProjectAndCollection(w, r)
+	if err != nil {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
+	if err != nil {
+		return
+	}
+
+	opts := parseQueryOptions(r)
+	flusher, _ := w.(http.Flusher)
+	written := 0
+
+	err = h.docService.StreamDocuments(r.Context(), project.ID, collectionName, schema, opts, func(doc *model.MockDocument) error {
+		if written == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", collectionName+".ndjson"))
+			w.WriteHeader(http.StatusOK)
+		}
+
+		line := orderDocument(doc.ToClean())
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+
+		written++
+		if flusher != nil && written%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+
+	switch {
+	case err != nil && written == 0:
+		writeServiceError(w, err, http.StatusInternalServerError)
+	case err != nil:
+		// Заголовки уже отправлены: разрываем соединение, чтобы клиент не принял
+		// неполный экспорт за весь
+		slog.Error("NDJSON export failed",
+			slog.String("collection", collectionName),
+			slog.Int("written", written),
+			slog.String("error", err.Error()))
+		panic(http.ErrAbortHandler)
+	case written == 0:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// importNDJSON создает документы из NDJSON-тела запроса, читая его потоком
+func (h *DocumentHandler) importNDJSON(w http.ResponseWriter, r *http.Request, project *project.ProjectInfo, collectionName string) {
+	schema, err := h.getCollectionSchema(w, r, project, collectionName)
+	if err != nil {
+		return
+	}
+
+	response, err := h.docService.ImportNDJSON(project.ID, collectionName, schema, r.Body)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, response)
+}
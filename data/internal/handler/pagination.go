@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/model"
+)
+
+// defaultPerPage размер страницы, если указан только page
+const defaultPerPage = 10
+
+// setPaginationHeaders выставляет X-Total-Count и Link (RFC 5988) для списка документов
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, opts model.QueryOptions, response *model.DocumentsResponse) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(response.Total, 10))
+
+	if opts.Cursor != nil {
+		setCursorHeaders(w, r, response)
+		return
+	}
+
+	// Без limit все документы на одной странице - ссылки не нужны
+	if response.Limit <= 0 {
+		return
+	}
+
+	limit, offset, total := response.Limit, response.Offset, response.Total
+	lastOffset := int64(0)
+	if total > 0 {
+		lastOffset = (total - 1) / limit * limit
+	}
+
+	var links []string
+	addLink := func(rel string, linkOffset int64) {
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, pageURL(r, opts, limit, linkOffset), rel))
+	}
+
+	addLink("first", 0)
+	if offset > 0 {
+		addLink("prev", max(offset-limit, 0))
+	}
+	if offset+limit < total {
+		addLink("next", offset+limit)
+	}
+	addLink("last", lastOffset)
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// setCursorHeaders выставляет X-Next-Cursor и Link (first, next) в курсорном режиме
+func setCursorHeaders(w http.ResponseWriter, r *http.Request, response *model.DocumentsResponse) {
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, cursorURL(r, ""))}
+
+	if response.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", response.NextCursor)
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorURL(r, response.NextCursor)))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// paginationMeta формирует meta для ответа в формате {data, meta}
+func paginationMeta(opts model.QueryOptions, response *model.DocumentsResponse) model.PaginationMeta {
+	meta := model.PaginationMeta{
+		Total:  response.Total,
+		Limit:  response.Limit,
+		Offset: response.Offset,
+	}
+
+	if opts.Cursor != nil {
+		meta.NextCursor = response.NextCursor
+		return meta
+	}
+
+	if response.Limit > 0 {
+		meta.Page = response.Offset/response.Limit + 1
+		meta.PerPage = response.Limit
+		meta.TotalPages = (response.Total + response.Limit - 1) / response.Limit
+	}
+
+	return meta
+}
+
+// pageURL строит публичный URL страницы, сохраняя остальные параметры запроса.
+// Стиль параметров (page/per_page или limit/offset) совпадает с исходным запросом
+func pageURL(r *http.Request, opts model.QueryOptions, limit, offset int64) string {
+	query := r.URL.Query()
+	if opts.PerPage != nil {
+		query.Set("page", strconv.FormatInt(offset/limit+1, 10))
+		query.Set("per_page", strconv.FormatInt(limit, 10))
+	} else {
+		query.Set("limit", strconv.FormatInt(limit, 10))
+		query.Set("offset", strconv.FormatInt(offset, 10))
+	}
+
+	u := publicURL(r)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// cursorURL строит публичный URL страницы в курсорном режиме
+func cursorURL(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+
+	u := publicURL(r)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// publicURL восстанавливает внешний URL запроса по заголовкам X-Forwarded-* от Gateway
+func publicURL(r *http.Request) *url.URL {
+	scheme := r.Header.Get("X-Forwarded-Proto")
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	host := r.Header.Get("X-Forwarded-Host")
+	if host == "" {
+		host = r.Host
+	}
+
+	return &url.URL{
+		Scheme: scheme,
+		Host:   host,
+		Path:   r.Header.Get("X-Forwarded-Prefix") + r.URL.Path,
+	}
+}
+
+func parsePositiveInt(value string) *int64 {
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	return &n
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
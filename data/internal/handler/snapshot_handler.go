@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/pkg/context"
+	"github.com/go-mockingcode/data/internal/service"
+)
+
+type SnapshotHandler struct {
+	snapshots *service.SnapshotService
+}
+
+func NewSnapshotHandler(snapshots *service.SnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshots: snapshots,
+	}
+}
+
+// SnapshotsResponse список снимков проекта
+type SnapshotsResponse struct {
+	Snapshots []model.Snapshot `json:"snapshots"`
+	Count     int              `json:"count"`
+}
+
+// ServeHTTP разбирает пути /_snapshots, /_snapshots/{name} и /_snapshots/{name}/_restore.
+// Имитация задержек, сбоев и условные ответы коллекций к снимкам не применяются
+func (h *SnapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	project, err := context.GetProjectInfo(r.Context())
+	if err != nil {
+		writeErrorJson(w, http.StatusUnauthorized, "Project not authenticated")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/_snapshots"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		switch r.Method {
+		case http.MethodGet:
+			h.ListSnapshots(w, r, project.ID)
+		case http.MethodPost:
+			h.CreateSnapshot(w, r, project.ID)
+		default:
+			writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	case len(parts) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			h.GetSnapshot(w, r, project.ID, parts[0])
+		case http.MethodDelete:
+			h.DeleteSnapshot(w, r, project.ID, parts[0])
+		default:
+			writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	case len(parts) == 2 && parts[1] == "_restore":
+		if r.Method != http.MethodPost {
+			writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.RestoreSnapshot(w, r, project.ID, parts[0])
+	default:
+		writeErrorJson(w, http.StatusNotFound, "Endpoint not found")
+	}
+}
+
+// ListSnapshots godoc
+// @Summary List snapshots
+// @Description List named snapshots of the project data, newest first
+// @Tags snapshots
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Success 200 {object} SnapshotsResponse
+// @Failure 401 {object} map[string]string
+// @Router /{api_key}/_snapshots [get]
+func (h *SnapshotHandler) ListSnapshots(w http.ResponseWriter, r *http.Request, projectID int64) {
+	snapshots, err := h.snapshots.ListSnapshots(projectID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, SnapshotsResponse{
+		Snapshots: snapshots,
+		Count:     len(snapshots),
+	})
+}
+
+// CreateSnapshot godoc
+// @Summary Create snapshot
+// @Description Save documents of the collections defined in the project and their auto-increment counters under a name
+// @Tags snapshots
+// @Accept json
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param request body model.CreateSnapshotRequest true "Snapshot name"
+// @Success 201 {object} model.Snapshot
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string "Snapshot with this name already exists"
+// @Router /{api_key}/_snapshots [post]
+func (h *SnapshotHandler) CreateSnapshot(w http.ResponseWriter, r *http.Request, projectID int64) {
+	var req model.CreateSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	snapshot, err := h.snapshots.CreateSnapshot(projectID, req.Name)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	writeSuccessJson(w, http.StatusCreated, snapshot)
+}
+
+// GetSnapshot godoc
+// @Summary Get snapshot
+// @Description Get snapshot collections with document counts
+// @Tags snapshots
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param name path string true "Snapshot name"
+// @Success 200 {object} model.Snapshot
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /{api_key}/_snapshots/{name} [get]
+func (h *SnapshotHandler) GetSnapshot(w http.ResponseWriter, r *http.Request, projectID int64, name string) {
+	snapshot, err := h.snapshots.GetSnapshot(projectID, name)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if snapshot == nil {
+		writeErrorJson(w, http.StatusNotFound, "Snapshot not found")
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, snapshot)
+}
+
+// DeleteSnapshot godoc
+// @Summary Delete snapshot
+// @Tags snapshots
+// @Param api_key path string true "API Key"
+// @Param name path string true "Snapshot name"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /{api_key}/_snapshots/{name} [delete]
+func (h *SnapshotHandler) DeleteSnapshot(w http.ResponseWriter, r *http.Request, projectID int64, name string) {
+	deleted, err := h.snapshots.DeleteSnapshot(projectID, name)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		writeErrorJson(w, http.StatusNotFound, "Snapshot not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreSnapshot godoc
+// @Summary Restore snapshot
+// @Description Replace documents and auto-increment counters of all project collections with the snapshot contents in one call. Collections absent from the snapshot are cleared. Collections are restored one by one in batches of 500 documents, each batch in its own MongoDB transaction, so MongoDB must run as a replica set (501 otherwise, unless DATA_SNAPSHOT_RESTORE_WITHOUT_TRANSACTION allows a restore without transactions). The restore as a whole is not atomic: a collection may be partially restored while it runs. Removed and restored documents are recorded in history with source "snapshot", so as_of reads see the restored state; webhooks and _stream subscribers get one collection.flushed event per collection
+// @Tags snapshots
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param name path string true "Snapshot name"
+// @Success 200 {object} model.Snapshot
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 501 {object} map[string]string "MongoDB does not support transactions"
+// @Router /{api_key}/_snapshots/{name}/_restore [post]
+func (h *SnapshotHandler) RestoreSnapshot(w http.ResponseWriter, r *http.Request, projectID int64, name string) {
+	snapshot, err := h.snapshots.RestoreSnapshot(projectID, name)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if snapshot == nil {
+		writeErrorJson(w, http.StatusNotFound, "Snapshot not found")
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, snapshot)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/service"
+	"golang.org/x/net/websocket"
+)
+
+// streamKeepAlive интервал комментариев SSE, которые не дают прокси закрыть простаивающее соединение
+const streamKeepAlive = 15 * time.Second
+
+// HandleStream godoc
+// @Summary Stream collection changes
+// @Description Push document change events of the collection as they happen: Server-Sent Events by default, WebSocket when the request is a WebSocket upgrade (one JSON event per text message). Events: document.created, document.updated, document.deleted (with data and previous document), collection.flushed and collection.generated (with count). Field filters (?status=active, ?price_gte=10) are checked against the changed document; collection events always pass them. Only changes made after connecting are sent; a client that falls too far behind is disconnected and should reconnect
+// @Tags documents
+// @Produce text/event-stream
+// @Param api_key path string true "API Key"
+// @Param collection path string true "Collection Name"
+// @Param _events query string false "Event types, comma separated: _events=document.created,document.deleted"
+// @Param field query string false "Filter by data field, same operators as the list endpoint"
+// @Success 200 {string} string "SSE stream: id, event and data (model.ChangeEvent JSON) per event"
+// @Success 101 "WebSocket connection"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 405 {object} map[string]string
+// @Router /{api_key}/{collection}/_stream [get]
+func (h *DocumentHandler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	project, collectionName, err := extractProjectAndCollection(w, r)
+	if err != nil {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var events []string
+	for _, event := range strings.Split(r.URL.Query().Get("_events"), ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+	filter, err := service.NewStreamFilter(events, parseFieldFilters(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		h.streamWebSocket(w, r, project.ID, collectionName, filter)
+		return
+	}
+	h.streamSSE(w, r, project.ID, collectionName, filter)
+}
+
+// streamSSE передает события в формате Server-Sent Events
+func (h *DocumentHandler) streamSSE(w http.ResponseWriter, r *http.Request, projectID int64, collectionName string, filter service.StreamFilter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeErrorJson(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	sub := h.feed.Subscribe(projectID, collectionName, filter)
+	defer h.feed.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Очередь переполнена: клиент переподключится (EventSource делает это сам)
+				return
+			}
+			data, _ := json.Marshal(event)
+			_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Event, data)
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// streamWebSocket передает события по WebSocket: одно событие JSON в текстовом сообщении
+func (h *DocumentHandler) streamWebSocket(w http.ResponseWriter, r *http.Request, projectID int64, collectionName string, filter service.StreamFilter) {
+	server := websocket.Server{
+		// Без Handshake Origin не проверяется: публичный API доступен с любых источников
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			sub := h.feed.Subscribe(projectID, collectionName, filter)
+			defer h.feed.Unsubscribe(sub)
+
+			// Сообщения клиента не ожидаются: чтение нужно, чтобы заметить закрытие соединения
+			closed := make(chan struct{})
+			go func() {
+				io.Copy(io.Discard, ws)
+				close(closed)
+			}()
+
+			for {
+				select {
+				case <-closed:
+					return
+				case event, ok := <-sub.Events():
+					if !ok {
+						return
+					}
+					if err := websocket.JSON.Send(ws, event); err != nil {
+						return
+					}
+				}
+			}
+		},
+	}
+	server.ServeHTTP(w, r)
+}
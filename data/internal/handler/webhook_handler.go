@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/pkg/context"
+	"github.com/go-mockingcode/data/internal/service"
+)
+
+type WebhookHandler struct {
+	webhooks *service.WebhookService
+}
+
+func NewWebhookHandler(webhooks *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+	}
+}
+
+// ServeHTTP разбирает путь /_webhooks/deliveries. Сами вебхуки настраиваются в project service
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	project, err := context.GetProjectInfo(r.Context())
+	if err != nil {
+		writeErrorJson(w, http.StatusUnauthorized, "Project not authenticated")
+		return
+	}
+
+	if strings.Trim(strings.TrimPrefix(r.URL.Path, "/_webhooks"), "/") != "deliveries" {
+		writeErrorJson(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	h.ListDeliveries(w, r, project.ID)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Delivery log of project webhooks, newest first: event, payload, status and every attempt with response code, error and duration. Failed attempts are retried with exponential backoff; pending deliveries show next_attempt_at
+// @Tags webhooks
+// @Produce json
+// @Param api_key path string true "API Key"
+// @Param webhook_id query int false "Only deliveries of the webhook"
+// @Param status query string false "Only deliveries with the status" Enums(pending, succeeded, failed)
+// @Param limit query int false "Limit (default 10)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} model.WebhookDeliveriesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /{api_key}/_webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request, projectID int64) {
+	var webhookID int64
+	if raw := r.URL.Query().Get("webhook_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			writeErrorJson(w, http.StatusBadRequest, "Invalid webhook ID")
+			return
+		}
+		webhookID = id
+	}
+
+	opts := parseQueryOptions(r)
+	if opts.Limit == nil {
+		opts.Limit = int64Ptr(defaultPerPage)
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(projectID, webhookID, r.URL.Query().Get("status"), opts)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, model.WebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Count:      len(deliveries),
+	})
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -11,9 +13,19 @@ type MockDocument struct {
 	ID             bson.ObjectID  `bson:"_id,omitempty" json:"id"`
 	ProjectID      int64          `bson:"project_id" json:"project_id"`
 	CollectionName string         `bson:"collection_name" json:"collection_name"`
-	Data           map[string]any `bson:"data" json:"data"` // Динамические данные
+	Data           map[string]any `bson:"data" json:"data"`       // Динамические данные
+	Version        int64          `bson:"version" json:"version"` // Увеличивается при каждом изменении (0 - документ создан до версионирования)
 	CreatedAt      time.Time      `bson:"created_at" json:"created_at"`
 	UpdatedAt      time.Time      `bson:"updated_at" json:"updated_at"`
+
+	// Значения уникальных полей схемы в виде "field=value", на них построен уникальный индекс
+	Unique []string `bson:"_unique,omitempty" json:"-"`
+}
+
+// ETag возвращает тег версии документа для заголовков ETag и If-Match.
+// Время изменения в теге отличает документ от удаленного и созданного заново с тем же id
+func (d *MockDocument) ETag() string {
+	return fmt.Sprintf(`"%d-%x"`, d.Version, d.UpdatedAt.UnixMilli())
 }
 
 // DocumentResponse ответ с документом
@@ -23,10 +35,24 @@ type DocumentResponse struct {
 
 // DocumentsResponse ответ с документами
 type DocumentsResponse struct {
-	Documents []*MockDocument `json:"documents"`
-	Total     int64           `json:"total"`
-	Limit     int64           `json:"limit"`
-	Offset    int64           `json:"offset"`
+	Documents  []*MockDocument `json:"documents"`
+	Total      int64           `json:"total"`
+	Limit      int64           `json:"limit"`
+	Offset     int64           `json:"offset"`
+	HasMore    bool            `json:"-"`                     // Есть следующая страница (курсорный режим)
+	NextCursor string          `json:"next_cursor,omitempty"` // Курсор следующей страницы
+}
+
+// PaginationMeta метаданные пагинации для ответа в формате {data, meta}
+type PaginationMeta struct {
+	Total      int64 `json:"total"`
+	Limit      int64 `json:"limit"`
+	Offset     int64 `json:"offset"`
+	Page       int64 `json:"page,omitempty"`
+	PerPage    int64 `json:"per_page,omitempty"`
+	TotalPages int64 `json:"total_pages,omitempty"`
+
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // CleanDocument - чистый формат документа для публичного API (только data + id)
@@ -51,10 +77,262 @@ type GenerateRequest struct {
 	Seed  *uint64 `json:"seed,omitempty" example:"12345"`
 }
 
+// GraphQLRequest запрос к GraphQL API проекта (GraphQL over HTTP)
+type GraphQLRequest struct {
+	Query         string         `json:"query" example:"{ users(limit: 5) { items { id name } total } }"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// PatchOperation операция JSON Patch (RFC 6902)
+type PatchOperation struct {
+	Op    string          `json:"op" example:"replace" enums:"add,remove,replace,move,copy,test"`
+	Path  string          `json:"path" example:"/name"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+// IdempotencyRecord сохраненный ответ на запрос с заголовком Idempotency-Key
+type IdempotencyRecord struct {
+	ProjectID   int64               `bson:"project_id"`
+	Key         string              `bson:"key"`
+	RequestHash string              `bson:"request_hash"` // Хеш метода, пути и тела запроса
+	Status      int                 `bson:"status"`       // 0 - запрос еще выполняется
+	Headers     map[string][]string `bson:"headers,omitempty"`
+	Body        []byte              `bson:"body,omitempty"`
+	Truncated   bool                `bson:"truncated,omitempty"` // Тело ответа больше лимита и не сохранено
+	CreatedAt   time.Time           `bson:"created_at"`
+	ExpiresAt   time.Time           `bson:"expires_at"` // Запись удаляется TTL-индексом
+}
+
+// Источники изменений документов в истории
+const (
+	ChangeSourceAPI       = "api"       // Публичный API
+	ChangeSourceUI        = "ui"        // Веб-интерфейс (заголовок X-Change-Source: ui)
+	ChangeSourceGenerator = "generator" // Генерация данных по схеме
+	ChangeSourceImport    = "import"    // Импорт CSV и NDJSON
+	ChangeSourceSnapshot  = "snapshot"  // Восстановление снимка данных
+)
+
+// Операции в истории изменений
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// DocumentChange запись истории изменений документа
+type DocumentChange struct {
+	ID             bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	ProjectID      int64         `bson:"project_id" json:"-"`
+	CollectionName string        `bson:"collection_name" json:"collection" example:"users"`
+	DocumentID     string        `bson:"document_id" json:"document_id" example:"1"`
+	Op             string        `bson:"op" json:"op" example:"update" enums:"create,update,delete"`
+	Version        int64         `bson:"version" json:"version" example:"2"` // Версия после изменения, для delete - удаленная
+	Before         CleanDocument `bson:"before,omitempty" json:"before,omitempty"`
+	After          CleanDocument `bson:"after,omitempty" json:"after,omitempty"`
+	Source         string        `bson:"source" json:"source" example:"api" enums:"api,ui,generator,import,snapshot"`
+	Timestamp      time.Time     `bson:"timestamp" json:"timestamp"`
+	ExpiresAt      *time.Time    `bson:"expires_at,omitempty" json:"-"` // Запись удаляется TTL-индексом
+}
+
+// ChangeSet изменения документов коллекции, выполненные одной операцией
+type ChangeSet struct {
+	ProjectID      int64
+	CollectionName string
+	Source         string
+	Flush          bool // Очистка коллекции целиком
+	Count          int  // Число удаленных при очистке документов (изменения очистки слушателям не передаются)
+	Changes        []DocumentChange
+}
+
+// HistoryResponse история изменений документа, новые изменения первыми
+type HistoryResponse struct {
+	Changes []DocumentChange `json:"changes"`
+	Count   int              `json:"count"`
+}
+
+// Snapshot именованный снимок всех коллекций проекта: документы и счетчики автоинкремента
+type Snapshot struct {
+	ID          bson.ObjectID        `bson:"_id,omitempty" json:"-"`
+	ProjectID   int64                `bson:"project_id" json:"-"`
+	Name        string               `bson:"snapshot_name" json:"name" example:"baseline"`
+	Collections []SnapshotCollection `bson:"collections" json:"collections"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// SnapshotCollection состояние коллекции в снимке
+type SnapshotCollection struct {
+	Name      string `bson:"name" json:"name" example:"users"`
+	Documents int64  `bson:"documents" json:"documents" example:"10"`
+	Counter   int    `bson:"counter" json:"counter" example:"10"` // Последний выданный автоинкрементный id
+}
+
+// CreateSnapshotRequest запрос на создание снимка
+type CreateSnapshotRequest struct {
+	Name string `json:"name" example:"baseline"`
+}
+
+// ChangeEvent событие изменения данных: тело запроса вебхука и сообщение потока _stream.
+// Для событий документов заполняются document_id, data (состояние после изменения)
+// и previous (до изменения), для событий коллекции - count
+type ChangeEvent struct {
+	ID         string        `json:"id" example:"6650f1c2a4b5c6d7e8f90a1b"` // ID события, общий для всех получателей
+	Event      string        `json:"event" example:"document.updated"`
+	ProjectID  int64         `json:"project_id" example:"1"`
+	Collection string        `json:"collection" example:"orders"`
+	Source     string        `json:"source" example:"api" enums:"api,ui,generator,import,snapshot"`
+	Timestamp  time.Time     `json:"timestamp"`
+	DocumentID string        `json:"document_id,omitempty" example:"1"`
+	Version    int64         `json:"version,omitempty" example:"2"`
+	Data       CleanDocument `json:"data,omitempty"`
+	Previous   CleanDocument `json:"previous,omitempty"`
+	Count      int           `json:"count,omitempty" example:"10"` // Число сгенерированных или удаленных документов
+}
+
+// Статусы доставки вебхука
+const (
+	DeliveryPending   = "pending"   // Ожидает отправки или повторной попытки
+	DeliverySucceeded = "succeeded" // Получатель ответил 2xx
+	DeliveryFailed    = "failed"    // Попытки исчерпаны
+)
+
+// WebhookDelivery запись журнала доставки: одно событие одному вебхуку
+type WebhookDelivery struct {
+	ID            bson.ObjectID    `bson:"_id,omitempty" json:"id"`
+	ProjectID     int64            `bson:"project_id" json:"-"`
+	WebhookID     int64            `bson:"webhook_id" json:"webhook_id" example:"1"`
+	URL           string           `bson:"url" json:"url" example:"http://localhost:9000/hooks"`
+	EventID       string           `bson:"event_id" json:"event_id"`
+	Event         string           `bson:"event" json:"event" example:"document.created"`
+	Collection    string           `bson:"collection" json:"collection" example:"orders"`
+	Payload       json.RawMessage  `bson:"payload" json:"payload" swaggertype:"object"` // Тело запроса
+	Status        string           `bson:"delivery_status" json:"status" example:"succeeded" enums:"pending,succeeded,failed"`
+	Attempts      []WebhookAttempt `bson:"attempts" json:"attempts"`
+	NextAttemptAt *time.Time       `bson:"next_attempt_at,omitempty" json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time        `bson:"updated_at" json:"updated_at"`
+	ExpiresAt     time.Time        `bson:"expires_at" json:"-"` // Запись удаляется TTL-индексом
+}
+
+// WebhookAttempt попытка доставки вебхука
+type WebhookAttempt struct {
+	At         time.Time `bson:"at" json:"at"`
+	StatusCode int       `bson:"status_code,omitempty" json:"status_code,omitempty" example:"200"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs int64     `bson:"duration_ms" json:"duration_ms" example:"12"`
+	Response   string    `bson:"response,omitempty" json:"response,omitempty"` // Начало тела ответа получателя
+}
+
+// WebhookDeliveriesResponse журнал доставок вебхуков, новые первыми
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Count      int               `json:"count"`
+}
+
+// FieldViolation нарушение схемы коллекции в поле документа
+type FieldViolation struct {
+	Field   string `json:"field" example:"email"`
+	Rule    string `json:"rule" example:"format" enums:"required,type,min,max,options,format,unknown"`
+	Message string `json:"message" example:"must be a valid email"`
+}
+
+// Операции пакетного запроса
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// BulkOperation операция пакетного запроса POST /{collection}/_bulk
+type BulkOperation struct {
+	Op   string         `json:"op" example:"create" enums:"create,update,delete"`
+	ID   any            `json:"id,omitempty" swaggertype:"string" example:"1"` // Для update и delete
+	Data map[string]any `json:"data,omitempty"`                                // Для create и update
+
+	Index      int    `json:"-"` // Позиция операции в запросе (заполняет сервис)
+	DocumentID string `json:"-"` // ID документа в строковом виде (заполняет сервис)
+}
+
+// BulkItemResult результат одной операции пакетного запроса
+type BulkItemResult struct {
+	Index    int           `json:"index"`
+	Op       string        `json:"op"`
+	Status   int           `json:"status" example:"201"`
+	ID       any           `json:"id,omitempty" swaggertype:"string"`
+	Document CleanDocument `json:"document,omitempty"`
+	Error    string        `json:"error,omitempty"`
+
+	Violations []FieldViolation `json:"violations,omitempty"` // Нарушения схемы коллекции (статус 422)
+}
+
+// BulkResponse ответ на пакетный запрос
+type BulkResponse struct {
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Deleted int              `json:"deleted"`
+	Failed  int              `json:"failed"`
+	Results []BulkItemResult `json:"results"`
+}
+
 // QueryOptions опции для запросов
 type QueryOptions struct {
 	Limit  *int64 `json:"limit,omitempty" example:"10"`
 	Offset *int64 `json:"offset,omitempty" example:"0"`
-	Sort   string `json:"sort,omitempty" example:"created_at"`
-	Order  string `json:"order,omitempty" example:"desc" enums:"asc,desc"`
+
+	// Альтернатива limit/offset: номер страницы (с 1) и размер страницы
+	Page    *int64 `json:"page,omitempty" example:"1"`
+	PerPage *int64 `json:"per_page,omitempty" example:"10"`
+
+	// Курсорная пагинация: пустая строка - первая страница, далее next_cursor из ответа
+	Cursor *string `json:"cursor,omitempty"`
+	After  []any   `json:"-"` // Значения ключей сортировки из cursor (заполняет сервис)
+
+	Sort  string `json:"sort,omitempty" example:"-price,name"`
+	Order string `json:"order,omitempty" example:"desc" enums:"asc,desc"`
+
+	SortKeys []SortKey `json:"-"` // Разобранные ключи сортировки (заполняет сервис)
+
+	Filters []FieldFilter `json:"-"` // Фильтры по полям data.*
+
+	Search       string   `json:"q,omitempty" example:"john"` // Полнотекстовый поиск по строковым полям
+	SearchFields []string `json:"-"`                          // Поля для поиска (пусто - все строковые поля data)
+}
+
+// Поля метаданных документа, доступные для сортировки наравне с полями data
+const (
+	MetaCreatedAt = "created_at"
+	MetaUpdatedAt = "updated_at"
+)
+
+// SortKey ключ сортировки
+type SortKey struct {
+	Field string // Поле data или поле метаданных (created_at, updated_at)
+	Desc  bool
+}
+
+// IsMeta проверяет, что сортировка идет по полю метаданных документа, а не по data
+func (k SortKey) IsMeta() bool {
+	return k.Field == MetaCreatedAt || k.Field == MetaUpdatedAt
+}
+
+// Операторы фильтрации (суффиксы query-параметров: ?price_gte=10)
+const (
+	FilterEq     = "eq"
+	FilterNe     = "ne"
+	FilterGt     = "gt"
+	FilterGte    = "gte"
+	FilterLt     = "lt"
+	FilterLte    = "lte"
+	FilterIn     = "in"
+	FilterLike   = "like"
+	FilterExists = "exists"
+)
+
+// FieldFilter условие фильтрации по полю документа
+type FieldFilter struct {
+	Field    string   // Имя поля в data (вложенные поля через точку: address.city)
+	Operator string   // Один из Filter* операторов
+	Raw      []string // Исходные значения из query string
+	Values   []any    // Значения после приведения типов (заполняет сервис)
 }
@@ -5,11 +5,21 @@ import (
 	"fmt"
 
 	"github.com/go-mockingcode/data/internal/pkg/project"
+	"github.com/go-mockingcode/models"
 )
 
 type contextKey string
 
-const ProjectKey contextKey = "project"
+const (
+	ProjectKey contextKey = "project"
+	SchemaKey  contextKey = "collection_schema"
+)
+
+// collectionSchema схема коллекции, загруженная один раз на запрос
+type collectionSchema struct {
+	name   string
+	schema *models.Collection
+}
 
 func GetProjectInfo(ctx context.Context) (*project.ProjectInfo, error) {
 	projectInfo, ok := ctx.Value(ProjectKey).(*project.ProjectInfo)
@@ -18,3 +28,17 @@ func GetProjectInfo(ctx context.Context) (*project.ProjectInfo, error) {
 	}
 	return projectInfo, nil
 }
+
+// WithCollectionSchema сохраняет схему коллекции запроса (nil для коллекций без схемы)
+func WithCollectionSchema(ctx context.Context, collectionName string, schema *models.Collection) context.Context {
+	return context.WithValue(ctx, SchemaKey, collectionSchema{name: collectionName, schema: schema})
+}
+
+// GetCollectionSchema возвращает сохраненную схему коллекции и признак, что она уже загружена
+func GetCollectionSchema(ctx context.Context, collectionName string) (*models.Collection, bool) {
+	cached, ok := ctx.Value(SchemaKey).(collectionSchema)
+	if !ok || cached.name != collectionName {
+		return nil, false
+	}
+	return cached.schema, true
+}
@@ -0,0 +1,58 @@
+package project
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache хранит ответы project сервиса по проекту не дольше ttl
+type ttlCache[T any] struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[int64]cacheEntry[T]
+}
+
+type cacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newTTLCache[T any](ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{
+		ttl:     ttl,
+		entries: make(map[int64]cacheEntry[T]),
+	}
+}
+
+// get возвращает значение из кэша или загружает его через load.
+// Ошибки загрузки не кэшируются. При ttl <= 0 кэш отключен
+func (c *ttlCache[T]) get(projectID int64, load func() (T, error)) (T, error) {
+	if c.ttl <= 0 {
+		return load()
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[projectID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	c.entries[projectID] = cacheEntry[T]{value: value, expiresAt: now.Add(c.ttl)}
+	// Устаревшие записи удаляются при записи, чтобы кэш не рос с числом проектов
+	for id, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	c.mu.Unlock()
+
+	return value, nil
+}
@@ -4,20 +4,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/go-mockingcode/models"
 )
 
 type ProjectClient struct {
-	baseURL string
-	client  *http.Client
+	baseURL     string
+	client      *http.Client
+	endpoints   *ttlCache[[]models.Endpoint]
+	webhooks    *ttlCache[[]models.Webhook]
+	collections *ttlCache[[]models.Collection]
+	settings    *ttlCache[*models.ProjectSettings]
 }
 
-func NewProjectClient(baseURL string) *ProjectClient {
+// NewProjectClient создает клиент project сервиса. cacheTTL - срок кэширования
+// пользовательских маршрутов, вебхуков, списка схем коллекций и настроек проекта (0 - без кэша)
+func NewProjectClient(baseURL string, cacheTTL time.Duration) *ProjectClient {
 	return &ProjectClient{
 		baseURL: baseURL,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		endpoints:   newTTLCache[[]models.Endpoint](cacheTTL),
+		webhooks:    newTTLCache[[]models.Webhook](cacheTTL),
+		collections: newTTLCache[[]models.Collection](cacheTTL),
+		settings:    newTTLCache[*models.ProjectSettings](cacheTTL),
 	}
 }
 
@@ -61,3 +74,165 @@ func (c *ProjectClient) ValidateAPIKey(apiKey string) (*ProjectInfo, error) {
 
 	return result.Project, nil
 }
+
+// GetCollection возвращает схему коллекции проекта по имени.
+// Возвращает nil без ошибки, если схема не описана (schema-less режим)
+func (c *ProjectClient) GetCollection(projectID int64, collectionName string) (*models.Collection, error) {
+	path := fmt.Sprintf("%s/internal/projects/%d/collections/%s", c.baseURL, projectID, url.PathEscape(collectionName))
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("project service returned status: %d", resp.StatusCode)
+	}
+
+	var collection models.Collection
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+// GetCollections возвращает схемы всех коллекций проекта (для GraphQL и снимков).
+// Ответ кэшируется на cacheTTL, поэтому изменения схем применяются с задержкой
+func (c *ProjectClient) GetCollections(projectID int64) ([]models.Collection, error) {
+	return c.collections.get(projectID, func() ([]models.Collection, error) {
+		return c.fetchCollections(projectID)
+	})
+}
+
+func (c *ProjectClient) fetchCollections(projectID int64) ([]models.Collection, error) {
+	path := fmt.Sprintf("%s/internal/projects/%d/collections", c.baseURL, projectID)
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("project service returned status: %d", resp.StatusCode)
+	}
+
+	var collections []models.Collection
+	if err := json.NewDecoder(resp.Body).Decode(&collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// GetSettings возвращает настройки публичного API проекта.
+// Ответ кэшируется на cacheTTL, как и пользовательские маршруты
+func (c *ProjectClient) GetSettings(projectID int64) (*models.ProjectSettings, error) {
+	return c.settings.get(projectID, func() (*models.ProjectSettings, error) {
+		return c.fetchSettings(projectID)
+	})
+}
+
+func (c *ProjectClient) fetchSettings(projectID int64) (*models.ProjectSettings, error) {
+	path := fmt.Sprintf("%s/internal/projects/%d/settings", c.baseURL, projectID)
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("project service returned status: %d", resp.StatusCode)
+	}
+
+	var settings models.ProjectSettings
+	if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// GetEndpoints возвращает включенные пользовательские маршруты проекта.
+// Ответ кэшируется на cacheTTL, поэтому изменения маршрутов применяются с задержкой
+func (c *ProjectClient) GetEndpoints(projectID int64) ([]models.Endpoint, error) {
+	return c.endpoints.get(projectID, func() ([]models.Endpoint, error) {
+		return c.fetchEndpoints(projectID)
+	})
+}
+
+func (c *ProjectClient) fetchEndpoints(projectID int64) ([]models.Endpoint, error) {
+	path := fmt.Sprintf("%s/internal/projects/%d/endpoints", c.baseURL, projectID)
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("project service returned status: %d", resp.StatusCode)
+	}
+
+	var endpoints []models.Endpoint
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// GetWebhooks возвращает включенные вебхуки проекта.
+// Ответ кэшируется на cacheTTL, как и пользовательские маршруты
+func (c *ProjectClient) GetWebhooks(projectID int64) ([]models.Webhook, error) {
+	return c.webhooks.get(projectID, func() ([]models.Webhook, error) {
+		return c.fetchWebhooks(projectID)
+	})
+}
+
+func (c *ProjectClient) fetchWebhooks(projectID int64) ([]models.Webhook, error) {
+	path := fmt.Sprintf("%s/internal/projects/%d/webhooks", c.baseURL, projectID)
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("project service returned status: %d", resp.StatusCode)
+	}
+
+	var webhooks []models.Webhook
+	if err := json.NewDecoder(resp.Body).Decode(&webhooks); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
@@ -2,9 +2,15 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-mockingcode/data/internal/model"
@@ -13,15 +19,27 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// ErrDuplicateValue значение уникального поля уже есть в коллекции
+var ErrDuplicateValue = errors.New("duplicate value")
+
+// ErrVersionMismatch документ изменился после чтения (не совпала ожидаемая версия)
+var ErrVersionMismatch = errors.New("document version mismatch")
+
 type DocumentRepository struct {
-	client *mongo.Client
-	dbName string
+	client     *mongo.Client
+	dbName     string
+	historyTTL time.Duration // Срок хранения истории изменений (0 - бессрочно)
+	listeners  []ChangeListener
+
+	// Коллекции MongoDB, в которых уже создан уникальный индекс _unique
+	uniqueIndexes sync.Map
 }
 
-func NewDocumentRepository(client *mongo.Client, dbName string) *DocumentRepository {
+func NewDocumentRepository(client *mongo.Client, dbName string, historyTTL time.Duration) *DocumentRepository {
 	return &DocumentRepository{
-		client: client,
-		dbName: dbName,
+		client:     client,
+		dbName:     dbName,
+		historyTTL: historyTTL,
 	}
 }
 
@@ -29,8 +47,47 @@ func (r *DocumentRepository) GetCollection(collectionName string) *mongo.Collect
 	return r.client.Database(r.dbName).Collection(collectionName)
 }
 
-// CreateDocument создает новый документ
-func (r *DocumentRepository) CreateDocument(projectID int64, collectionName string, data map[string]any) (*model.MockDocument, error) {
+// CreateDocument создает новый документ. unique - уникальные поля схемы,
+// source - источник изменения для истории
+func (r *DocumentRepository) CreateDocument(projectID int64, collectionName string, data map[string]any, unique []string, source string) (*model.MockDocument, error) {
+	if err := r.ensureUniqueIndex(collectionName, unique); err != nil {
+		return nil, err
+	}
+	doc, err := r.insertDocument(projectID, collectionName, data, unique)
+	if err != nil {
+		return nil, err
+	}
+	r.recordChanges(projectID, collectionName, source, []model.DocumentChange{newChange(model.ChangeCreate, nil, doc)})
+	return doc, nil
+}
+
+// CreateDocuments создает документы по одному и записывает их в историю одной операцией.
+// При ошибке возвращает вместе с ней уже созданные документы
+func (r *DocumentRepository) CreateDocuments(projectID int64, collectionName string, items []map[string]any, unique []string, source string) ([]*model.MockDocument, error) {
+	if err := r.ensureUniqueIndex(collectionName, unique); err != nil {
+		return nil, err
+	}
+
+	var docs []*model.MockDocument
+	var err error
+	for _, data := range items {
+		var doc *model.MockDocument
+		if doc, err = r.insertDocument(projectID, collectionName, data, unique); err != nil {
+			break
+		}
+		docs = append(docs, doc)
+	}
+
+	changes := make([]model.DocumentChange, len(docs))
+	for i, doc := range docs {
+		changes[i] = newChange(model.ChangeCreate, nil, doc)
+	}
+	r.recordChanges(projectID, collectionName, source, changes)
+	return docs, err
+}
+
+// insertDocument вставляет документ, назначая автоинкрементный id, если его нет в data
+func (r *DocumentRepository) insertDocument(projectID int64, collectionName string, data map[string]any, unique []string) (*model.MockDocument, error) {
 	collection := r.GetCollection(collectionName)
 	ctx := context.Background()
 
@@ -47,11 +104,16 @@ func (r *DocumentRepository) CreateDocument(projectID int64, collectionName stri
 		ProjectID:      projectID,
 		CollectionName: collectionName,
 		Data:           data,
+		Version:        1,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
+		Unique:         uniqueKeys(data, unique),
 	}
 
 	result, err := collection.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, duplicateValueError(err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create document: %v", err)
 	}
@@ -62,7 +124,13 @@ func (r *DocumentRepository) CreateDocument(projectID int64, collectionName stri
 
 // getNextID получает следующий автоинкрементный ID для коллекции проекта
 func (r *DocumentRepository) getNextID(projectID int64, collectionName string) (int, error) {
-	counterCollection := r.client.Database(r.dbName).Collection("counters")
+	return r.reserveIDs(projectID, collectionName, 1)
+}
+
+// reserveIDs резервирует n автоинкрементных ID одним обновлением счетчика
+// и возвращает последний из них (зарезервированы last-n+1..last)
+func (r *DocumentRepository) reserveIDs(projectID int64, collectionName string, n int) (int, error) {
+	counterCollection := r.client.Database(r.dbName).Collection(countersCollection)
 	ctx := context.Background()
 
 	filter := bson.M{
@@ -71,7 +139,7 @@ func (r *DocumentRepository) getNextID(projectID int64, collectionName string) (
 	}
 
 	update := bson.M{
-		"$inc": bson.M{"seq": 1},
+		"$inc": bson.M{"seq": n},
 	}
 
 	opts := options.FindOneAndUpdate().
@@ -95,32 +163,36 @@ func (r *DocumentRepository) GetDocuments(projectID int64, collectionName string
 	collection := r.GetCollection(collectionName)
 	ctx := context.Background()
 
-	// Фильтр по project_id
-	filter := bson.M{"project_id": projectID}
+	// Фильтр по project_id, полям data.* и поиску
+	filter := buildDocumentsFilter(projectID, opts)
 
 	// Настройки пагинации
 	findOptions := options.Find()
+	findFilter := filter
 
-	if opts.Limit != nil {
-		findOptions.SetLimit(*opts.Limit)
-	}
-	if opts.Offset != nil {
-		findOptions.SetSkip(*opts.Offset)
-	}
-
-	// Сортировка
-	if opts.Sort != "" {
-		order := int32(1) // asc
-		if opts.Order == "desc" {
-			order = int32(-1)
+	if opts.Cursor != nil {
+		// Курсорный режим: вместо skip продолжаем после значений ключей сортировки,
+		// лишний документ показывает, есть ли следующая страница
+		if len(opts.After) > 0 {
+			findFilter = bson.M{"$and": bson.A{filter, keysetCondition(opts.SortKeys, opts.After)}}
+		}
+		if opts.Limit != nil {
+			findOptions.SetLimit(*opts.Limit + 1)
 		}
-		findOptions.SetSort(bson.D{{Key: opts.Sort, Value: order}})
 	} else {
-		findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+		if opts.Limit != nil {
+			findOptions.SetLimit(*opts.Limit)
+		}
+		if opts.Offset != nil {
+			findOptions.SetSkip(*opts.Offset)
+		}
 	}
 
+	// Сортировка
+	findOptions.SetSort(buildSort(opts.SortKeys))
+
 	// Получаем документы
-	cursor, err := collection.Find(ctx, filter, findOptions)
+	cursor, err := collection.Find(ctx, findFilter, findOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find documents: %v", err)
 	}
@@ -131,6 +203,12 @@ func (r *DocumentRepository) GetDocuments(projectID int64, collectionName string
 		return nil, fmt.Errorf("failed to decode documents: %v", err)
 	}
 
+	hasMore := false
+	if opts.Cursor != nil && opts.Limit != nil && int64(len(documents)) > *opts.Limit {
+		documents = documents[:*opts.Limit]
+		hasMore = true
+	}
+
 	// Получаем общее количество
 	total, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -142,30 +220,262 @@ func (r *DocumentRepository) GetDocuments(projectID int64, collectionName string
 		Total:     total,
 		Limit:     getInt64Value(opts.Limit, 0),
 		Offset:    getInt64Value(opts.Offset, 0),
+		HasMore:   hasMore,
 	}, nil
 }
 
+// StreamDocuments передает в fn документы коллекции по одному, читая курсор MongoDB
+// порциями, без загрузки всей коллекции в память. Пагинация opts не применяется
+func (r *DocumentRepository) StreamDocuments(ctx context.Context, projectID int64, collectionName string, opts model.QueryOptions, fn func(*model.MockDocument) error) error {
+	collection := r.GetCollection(collectionName)
+
+	findOptions := options.Find().
+		SetSort(buildSort(opts.SortKeys)).
+		SetBatchSize(streamBatchSize)
+
+	cursor, err := collection.Find(ctx, buildDocumentsFilter(projectID, opts), findOptions)
+	if err != nil {
+		return fmt.Errorf("failed to find documents: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc model.MockDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode document: %v", err)
+		}
+		if err := fn(&doc); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read documents: %v", err)
+	}
+	return nil
+}
+
+// streamBatchSize количество документов, получаемых из MongoDB за одно обращение к курсору
+const streamBatchSize = 500
+
+// buildDocumentsFilter строит MongoDB фильтр по project_id, условиям на поля data.* и поиску
+func buildDocumentsFilter(projectID int64, opts model.QueryOptions) bson.M {
+	filter := bson.M{"project_id": projectID}
+
+	// Каждое условие отдельным элементом $and, чтобы несколько фильтров
+	// по одному полю (price_gte и price_lte) не перезаписывали друг друга
+	conditions := bson.A{}
+	for _, f := range opts.Filters {
+		conditions = append(conditions, bson.M{"data." + f.Field: fieldCondition(f)})
+	}
+	if opts.Search != "" {
+		conditions = append(conditions, searchCondition(opts.Search, opts.SearchFields))
+	}
+
+	if len(conditions) > 0 {
+		filter["$and"] = conditions
+	}
+	return filter
+}
+
+// buildSort строит сортировку по полям data.* и метаданным (по умолчанию - created_at desc)
+func buildSort(keys []model.SortKey) bson.D {
+	if len(keys) == 0 {
+		return bson.D{{Key: model.MetaCreatedAt, Value: -1}}
+	}
+
+	sort := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		order := int32(1) // asc
+		if key.Desc {
+			order = int32(-1)
+		}
+		sort = append(sort, bson.E{Key: sortPath(key), Value: order})
+	}
+	return sort
+}
+
+// sortPath возвращает путь поля сортировки в документе MongoDB
+func sortPath(key model.SortKey) string {
+	if key.IsMeta() {
+		return key.Field
+	}
+	return "data." + key.Field
+}
+
+// keysetCondition выбирает документы, идущие в порядке сортировки строго после
+// документа со значениями ключей after: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetCondition(keys []model.SortKey, after []any) bson.M {
+	or := make(bson.A, 0, len(keys))
+	for i, key := range keys {
+		next, ok := keysetAfter(sortPath(key), after[i], key.Desc)
+		if !ok {
+			continue
+		}
+
+		// Равенство с null выбирает и документы без поля - они сортируются так же
+		cond := make(bson.A, 0, i+1)
+		for j := 0; j < i; j++ {
+			cond = append(cond, bson.M{sortPath(keys[j]): after[j]})
+		}
+		or = append(or, bson.M{"$and": append(cond, next)})
+	}
+
+	if len(or) == 0 {
+		return bson.M{"_id": bson.M{"$exists": false}}
+	}
+	return bson.M{"$or": or}
+}
+
+// bsonTypeOrder группы типов BSON в порядке сортировки MongoDB.
+// null и отсутствующее поле идут раньше всех
+var bsonTypeOrder = [][]string{
+	{"double", "int", "long", "decimal"},
+	{"string", "symbol"},
+	{"object"},
+	{"array"},
+	{"binData"},
+	{"objectId"},
+	{"bool"},
+	{"date"},
+	{"timestamp"},
+	{"regex"},
+}
+
+// bsonTypeRank возвращает позицию типа значения в bsonTypeOrder (-1 - тип неизвестен)
+func bsonTypeRank(value any) int {
+	switch value.(type) {
+	case int, int32, int64, float32, float64, bson.Decimal128:
+		return 0
+	case string, bson.Symbol:
+		return 1
+	case bson.D, bson.M, map[string]any:
+		return 2
+	case bson.A, []any:
+		return 3
+	case bson.Binary:
+		return 4
+	case bson.ObjectID:
+		return 5
+	case bool:
+		return 6
+	case time.Time, bson.DateTime:
+		return 7
+	case bson.Timestamp:
+		return 8
+	case bson.Regex:
+		return 9
+	default:
+		return -1
+	}
+}
+
+// keysetAfter условие "значение поля идет в порядке сортировки после value".
+// $gt и $lt сравнивают только значения одного типа, поэтому документы, у которых
+// поле другого типа или отсутствует, отбираются отдельно по порядку типов BSON.
+// ok=false - после value документов быть не может (null при сортировке по убыванию)
+func keysetAfter(path string, value any, desc bool) (bson.M, bool) {
+	if value == nil {
+		if desc {
+			return nil, false
+		}
+		return bson.M{path: bson.M{"$ne": nil}}, true
+	}
+
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
+	compare := bson.M{path: bson.M{op: value}}
+
+	rank := bsonTypeRank(value)
+	if rank < 0 {
+		return compare, true
+	}
+
+	var types bson.A
+	for i, group := range bsonTypeOrder {
+		if desc && i < rank || !desc && i > rank {
+			for _, t := range group {
+				types = append(types, t)
+			}
+		}
+	}
+
+	or := bson.A{compare}
+	if len(types) > 0 {
+		or = append(or, bson.M{path: bson.M{"$type": types}})
+	}
+	if desc {
+		or = append(or, bson.M{path: nil})
+	}
+	return bson.M{"$or": or}, true
+}
+
+// searchCondition ищет подстроку без учета регистра в указанных полях data.
+// Если поля не указаны, проверяются все строковые поля верхнего уровня data
+func searchCondition(search string, fields []string) bson.M {
+	pattern := regexp.QuoteMeta(search)
+
+	if len(fields) > 0 {
+		or := make(bson.A, len(fields))
+		for i, field := range fields {
+			or[i] = bson.M{"data." + field: bson.Regex{Pattern: pattern, Options: "i"}}
+		}
+		return bson.M{"$or": or}
+	}
+
+	matches := bson.M{"$filter": bson.M{
+		"input": bson.M{"$objectToArray": "$data"},
+		"cond": bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$type": "$$this.v"}, "string"}},
+			bson.M{"$regexMatch": bson.M{"input": "$$this.v", "regex": pattern, "options": "i"}},
+		}},
+	}}
+	return bson.M{"$expr": bson.M{"$gt": bson.A{bson.M{"$size": matches}, 0}}}
+}
+
+func fieldCondition(f model.FieldFilter) any {
+	switch f.Operator {
+	case model.FilterNe:
+		if len(f.Values) == 1 {
+			return bson.M{"$ne": f.Values[0]}
+		}
+		return bson.M{"$nin": f.Values}
+	case model.FilterGt, model.FilterGte, model.FilterLt, model.FilterLte:
+		return bson.M{"$" + f.Operator: f.Values[0]}
+	case model.FilterIn:
+		return bson.M{"$in": f.Values}
+	case model.FilterLike:
+		patterns := make(bson.A, len(f.Values))
+		for i, v := range f.Values {
+			patterns[i] = bson.Regex{Pattern: fmt.Sprint(v), Options: "i"}
+		}
+		if len(patterns) == 1 {
+			return patterns[0]
+		}
+		return bson.M{"$in": patterns}
+	case model.FilterExists:
+		return bson.M{"$exists": f.Values[0]}
+	default:
+		if len(f.Values) == 1 {
+			return f.Values[0]
+		}
+		return bson.M{"$in": f.Values}
+	}
+}
+
 // GetDocumentByID возвращает документ по ID (ищет по data.id)
 func (r *DocumentRepository) GetDocumentByID(projectID int64, collectionName, documentID string) (*model.MockDocument, error) {
 	collection := r.GetCollection(collectionName)
 	ctx := context.Background()
 
-	// Пробуем преобразовать в число
-	var filter bson.M
-	if numID, err := strconv.Atoi(documentID); err == nil {
-		// Числовой ID - ищем по data.id
-		filter = bson.M{"project_id": projectID, "data.id": numID}
-	} else {
-		// Строковый ID - может быть MongoDB ObjectID (для обратной совместимости)
-		objID, err := bson.ObjectIDFromHex(documentID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid document ID: %v", err)
-		}
-		filter = bson.M{"_id": objID, "project_id": projectID}
+	filter, err := documentIDFilter(projectID, documentID)
+	if err != nil {
+		return nil, err
 	}
 
 	var doc model.MockDocument
-	err := collection.FindOne(ctx, filter).Decode(&doc)
+	err = collection.FindOne(ctx, filter).Decode(&doc)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -176,52 +486,188 @@ func (r *DocumentRepository) GetDocumentByID(projectID int64, collectionName, do
 	return &doc, nil
 }
 
-// UpdateDocument обновляет документ (ищет по data.id)
-func (r *DocumentRepository) UpdateDocument(projectID int64, collectionName, documentID string, data map[string]interface{}) (*model.MockDocument, error) {
+// FindByField возвращает документы коллекции проекта, у которых поле data
+// равно одному из values (для полей-массивов - содержит одно из values)
+func (r *DocumentRepository) FindByField(projectID int64, collectionName, field string, values []any) ([]*model.MockDocument, error) {
+	collection := r.GetCollection(collectionName)
+	ctx := context.Background()
+
+	filter := bson.M{"project_id": projectID, "data." + field: bson.M{"$in": values}}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var documents []*model.MockDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("failed to decode documents: %v", err)
+	}
+	return documents, nil
+}
+
+// documentIDFilter строит фильтр документа по ID: числовой ID ищется по data.id,
+// строковый - как MongoDB ObjectID (для обратной совместимости)
+func documentIDFilter(projectID int64, documentID string) (bson.M, error) {
+	if numID, err := strconv.Atoi(documentID); err == nil {
+		return bson.M{"project_id": projectID, "data.id": numID}, nil
+	}
+
+	objID, err := bson.ObjectIDFromHex(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document ID: %v", err)
+	}
+	return bson.M{"_id": objID, "project_id": projectID}, nil
+}
+
+// UpdateDocument обновляет документ (ищет по data.id). Если задана expectedVersion,
+// документ обновляется только в этой версии, иначе возвращается ErrVersionMismatch
+func (r *DocumentRepository) UpdateDocument(projectID int64, collectionName, documentID string, data map[string]interface{}, unique []string, expectedVersion *int64, source string) (*model.MockDocument, error) {
 	collection := r.GetCollection(collectionName)
 	ctx := context.Background()
 
-	// Пробуем преобразовать в число
-	var filter bson.M
+	if err := r.ensureUniqueIndex(collectionName, unique); err != nil {
+		return nil, err
+	}
+
+	filter, err := documentIDFilter(projectID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil {
+		filter["version"] = versionCondition(*expectedVersion)
+	}
+	// Убеждаемся что числовой id не изменяется
 	if numID, err := strconv.Atoi(documentID); err == nil {
-		// Числовой ID - ищем по data.id и сохраняем id в data
-		filter = bson.M{"project_id": projectID, "data.id": numID}
-		// Убеждаемся что id не изменяется
 		data["id"] = numID
-	} else {
-		// Строковый ID - может быть MongoDB ObjectID
-		objID, err := bson.ObjectIDFromHex(documentID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid document ID: %v", err)
+	}
+
+	update := dataUpdate(data, unique, time.Now())
+
+	// Прежнее состояние нужно для истории, новое читаем отдельно
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.Before)
+
+	var before model.MockDocument
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		if expectedVersion != nil {
+			return nil, ErrVersionMismatch
 		}
-		filter = bson.M{"_id": objID, "project_id": projectID}
+		return nil, nil
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, duplicateValueError(err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update document: %v", err)
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"data":       data,
-			"updated_at": time.Now(),
-		},
+	var doc model.MockDocument
+	if err := collection.FindOne(ctx, bson.M{"_id": before.ID}).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to find updated document: %v", err)
+	}
+
+	r.recordChanges(projectID, collectionName, source, []model.DocumentChange{newChange(model.ChangeUpdate, &before, &doc)})
+	return &doc, nil
+}
+
+// ReplaceDocumentData заменяет data документа, только если он не изменился с момента
+// чтения (сравнение по updated_at). Возвращает nil, если документ был изменен параллельно
+func (r *DocumentRepository) ReplaceDocumentData(doc *model.MockDocument, data map[string]any, unique []string, source string) (*model.MockDocument, error) {
+	collection := r.GetCollection(doc.CollectionName)
+	ctx := context.Background()
+
+	if err := r.ensureUniqueIndex(doc.CollectionName, unique); err != nil {
+		return nil, err
 	}
 
+	filter := bson.M{
+		"_id":        doc.ID,
+		"project_id": doc.ProjectID,
+		"updated_at": doc.UpdatedAt,
+	}
+
+	update := dataUpdate(data, unique, nextUpdatedAt(doc.UpdatedAt))
+
 	opts := options.FindOneAndUpdate().
 		SetReturnDocument(options.After)
 
-	var doc model.MockDocument
-	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+	var updated model.MockDocument
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, duplicateValueError(err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update document: %v", err)
 	}
 
-	return &doc, nil
+	r.recordChanges(doc.ProjectID, doc.CollectionName, source, []model.DocumentChange{newChange(model.ChangeUpdate, doc, &updated)})
+	return &updated, nil
+}
+
+// versionCondition условие на версию документа; версия 0 соответствует
+// документам, созданным до появления поля version
+func versionCondition(version int64) any {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// nextUpdatedAt возвращает новое значение updated_at, гарантированно отличное от
+// предыдущего с учетом миллисекундной точности дат MongoDB
+func nextUpdatedAt(previous time.Time) time.Time {
+	now := time.Now().Truncate(time.Millisecond)
+	if !now.After(previous) {
+		now = previous.Add(time.Millisecond)
+	}
+	return now
+}
+
+// advanceCounter сдвигает счетчик вперед, чтобы автоинкрементные ID не совпали
+// с уже занятыми явно заданными ID
+func (r *DocumentRepository) advanceCounter(projectID int64, collectionName string, seq int) error {
+	counterCollection := r.client.Database(r.dbName).Collection(countersCollection)
+
+	filter := bson.M{
+		"project_id":      projectID,
+		"collection_name": collectionName,
+	}
+	update := bson.M{
+		"$max": bson.M{"seq": seq},
+	}
+
+	_, err := counterCollection.UpdateOne(context.Background(), filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to advance counter: %v", err)
+	}
+	return nil
+}
+
+// intID возвращает числовой ID документа (из JSON приходит float64)
+func intID(id any) (int, bool) {
+	switch v := id.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), true
+		}
+	}
+	return 0, false
 }
 
 // ResetCounter сбрасывает автоинкрементный счетчик для коллекции проекта
 func (r *DocumentRepository) ResetCounter(projectID int64, collectionName string) error {
-	counterCollection := r.client.Database(r.dbName).Collection("counters")
+	counterCollection := r.client.Database(r.dbName).Collection(countersCollection)
 	ctx := context.Background()
 
 	filter := bson.M{
@@ -247,40 +693,39 @@ func (r *DocumentRepository) ResetCounter(projectID int64, collectionName string
 		return fmt.Errorf("failed to reset counter: %v", err)
 	}
 
-	slog.Info("Successfully reset counter", 
-		slog.Int64("project_id", projectID), 
-		slog.String("collection", collectionName), 
+	slog.Info("Successfully reset counter",
+		slog.Int64("project_id", projectID),
+		slog.String("collection", collectionName),
 		slog.Int("seq", result.Seq))
 	return nil
 }
 
-// DeleteDocument удаляет документ (ищет по data.id)
-func (r *DocumentRepository) DeleteDocument(projectID int64, collectionName, documentID string) error {
+// DeleteDocument удаляет документ (ищет по data.id). Если задана expectedVersion,
+// документ удаляется только в этой версии, иначе возвращается ErrVersionMismatch
+func (r *DocumentRepository) DeleteDocument(projectID int64, collectionName, documentID string, expectedVersion *int64, source string) error {
 	collection := r.GetCollection(collectionName)
 	ctx := context.Background()
 
-	// Пробуем преобразовать в число
-	var filter bson.M
-	if numID, err := strconv.Atoi(documentID); err == nil {
-		// Числовой ID - ищем по data.id
-		filter = bson.M{"project_id": projectID, "data.id": numID}
-	} else {
-		// Строковый ID - может быть MongoDB ObjectID
-		objID, err := bson.ObjectIDFromHex(documentID)
-		if err != nil {
-			return fmt.Errorf("invalid document ID: %v", err)
-		}
-		filter = bson.M{"_id": objID, "project_id": projectID}
-	}
-
-	result, err := collection.DeleteOne(ctx, filter)
+	filter, err := documentIDFilter(projectID, documentID)
 	if err != nil {
-		return fmt.Errorf("failed to delete document: %v", err)
+		return err
+	}
+	if expectedVersion != nil {
+		filter["version"] = versionCondition(*expectedVersion)
 	}
 
-	if result.DeletedCount == 0 {
+	var deleted model.MockDocument
+	err = collection.FindOneAndDelete(ctx, filter).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		if expectedVersion != nil {
+			return ErrVersionMismatch
+		}
 		return fmt.Errorf("document not found")
 	}
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	r.recordChanges(projectID, collectionName, source, []model.DocumentChange{newChange(model.ChangeDelete, &deleted, nil)})
 
 	// Проверяем, стала ли коллекция пустой после удаления
 	count, err := r.CountDocuments(projectID, collectionName)
@@ -292,8 +737,8 @@ func (r *DocumentRepository) DeleteDocument(projectID int64, collectionName, doc
 	if count == 0 {
 		if err := r.ResetCounter(projectID, collectionName); err != nil {
 			// Логируем ошибку, но не прерываем операцию удаления
-			slog.Warn("Failed to reset counter", 
-				slog.String("collection", collectionName), 
+			slog.Warn("Failed to reset counter",
+				slog.String("collection", collectionName),
 				slog.String("error", err.Error()))
 		}
 	}
@@ -302,30 +747,79 @@ func (r *DocumentRepository) DeleteDocument(projectID int64, collectionName, doc
 }
 
 // DeleteAllDocuments удаляет все документы коллекции
-func (r *DocumentRepository) DeleteAllDocuments(projectID int64, collectionName string) (int64, error) {
+func (r *DocumentRepository) DeleteAllDocuments(projectID int64, collectionName, source string) (int64, error) {
 	collection := r.GetCollection(collectionName)
 	ctx := context.Background()
 
 	filter := bson.M{"project_id": projectID}
 
+	// Удаляемые документы записываются в историю заранее, порциями по streamBatchSize.
+	// Документы, созданные во время очистки, удаляются без записи в историю
+	cursor, err := collection.Find(ctx, filter, options.Find().SetBatchSize(streamBatchSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find documents: %v", err)
+	}
+	changes := make([]model.DocumentChange, 0, streamBatchSize)
+	recordBatch := func() {
+		err := r.insertHistory(ctx, model.ChangeSet{
+			ProjectID:      projectID,
+			CollectionName: collectionName,
+			Source:         source,
+			Changes:        changes,
+		})
+		if err != nil {
+			slog.Warn("failed to record document history",
+				slog.Int64("project_id", projectID),
+				slog.String("collection", collectionName),
+				slog.String("error", err.Error()))
+		}
+		changes = changes[:0]
+	}
+	for cursor.Next(ctx) {
+		var doc model.MockDocument
+		if err := cursor.Decode(&doc); err != nil {
+			cursor.Close(ctx)
+			return 0, fmt.Errorf("failed to decode document: %v", err)
+		}
+		changes = append(changes, newChange(model.ChangeDelete, &doc, nil))
+		if len(changes) == streamBatchSize {
+			recordBatch()
+		}
+	}
+	err = cursor.Err()
+	cursor.Close(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find documents: %v", err)
+	}
+	recordBatch()
+
 	result, err := collection.DeleteMany(ctx, filter)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete documents: %v", err)
 	}
+	if result.DeletedCount > 0 {
+		r.notify(model.ChangeSet{
+			ProjectID:      projectID,
+			CollectionName: collectionName,
+			Source:         source,
+			Flush:          true,
+			Count:          int(result.DeletedCount),
+		})
+	}
 
 	// Если удалили документы, сбрасываем счетчик
 	if result.DeletedCount > 0 {
-		slog.Info("Deleted documents, resetting counter", 
-			slog.Int64("deleted_count", result.DeletedCount), 
-			slog.String("collection", collectionName), 
+		slog.Info("Deleted documents, resetting counter",
+			slog.Int64("deleted_count", result.DeletedCount),
+			slog.String("collection", collectionName),
 			slog.Int64("project_id", projectID))
 		if err := r.ResetCounter(projectID, collectionName); err != nil {
 			// Логируем ошибку, но не прерываем операцию удаления
-			slog.Warn("Failed to reset counter", 
-				slog.String("collection", collectionName), 
+			slog.Warn("Failed to reset counter",
+				slog.String("collection", collectionName),
 				slog.String("error", err.Error()))
 		} else {
-			slog.Info("Successfully reset counter", 
+			slog.Info("Successfully reset counter",
 				slog.String("collection", collectionName))
 		}
 	}
@@ -333,6 +827,301 @@ func (r *DocumentRepository) DeleteAllDocuments(projectID int64, collectionName
 	return result.DeletedCount, nil
 }
 
+// BulkWrite выполняет операции create/update/delete одним запросом BulkWrite.
+// Операции уже проверены сервисом; результат возвращается для каждой операции.
+// Запрос неупорядоченный: ошибка одной операции не отменяет остальные.
+// unique - уникальные поля схемы
+func (r *DocumentRepository) BulkWrite(projectID int64, collectionName string, ops []model.BulkOperation, unique []string, source string) ([]model.BulkItemResult, error) {
+	collection := r.GetCollection(collectionName)
+	ctx := context.Background()
+
+	if err := r.ensureUniqueIndex(collectionName, unique); err != nil {
+		return nil, err
+	}
+
+	// Существование документов для update/delete проверяем заранее:
+	// BulkWrite возвращает только суммарное количество совпадений
+	existing, err := r.existingDocuments(projectID, collectionName, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	// Автоинкрементные ID для новых документов резервируем одним обновлением счетчика
+	missingIDs := 0
+	maxID := 0 // Наибольший явно заданный числовой ID (документы, перенесенные из другой среды)
+	for _, op := range ops {
+		if op.Op != model.BulkCreate {
+			continue
+		}
+		id, hasID := op.Data["id"]
+		if !hasID {
+			missingIDs++
+		} else if numID, ok := intID(id); ok && numID > maxID {
+			maxID = numID
+		}
+	}
+	if maxID > 0 {
+		if err := r.advanceCounter(projectID, collectionName, maxID); err != nil {
+			return nil, err
+		}
+	}
+	nextID := 0
+	if missingIDs > 0 {
+		lastID, err := r.reserveIDs(projectID, collectionName, missingIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate IDs: %v", err)
+		}
+		nextID = lastID - missingIDs + 1
+	}
+
+	now := time.Now()
+	results := make([]model.BulkItemResult, len(ops))
+	var writes []mongo.WriteModel
+	var pending []bulkPending // Операция пакета для каждой модели в writes
+	var written []model.DocumentChange
+	// Документы и значения уникальных полей, которые меняют еще не отправленные модели.
+	// Несколько операций над одним документом или значением в неупорядоченном
+	// BulkWrite выполнились бы в случайном порядке
+	touched := make(map[string]bool)
+	deletes := false
+
+	// flush отправляет накопленные модели. Неудачные операции не попадают в историю,
+	// а состояние их документов в existing возвращается к прежнему
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		_, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+
+		failed := make(map[int]bool)
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+			for _, writeErr := range bulkErr.WriteErrors {
+				failed[writeErr.Index] = true
+				p := pending[writeErr.Index]
+				trackDocument(existing, p.after, p.before)
+
+				i := p.index
+				results[i].Status = http.StatusInternalServerError
+				results[i].Error = writeErr.Message
+				if mongo.IsDuplicateKeyError(writeErr) {
+					results[i].Status = http.StatusConflict
+					results[i].Error = duplicateValueError(writeErr).Error()
+				}
+				results[i].Document = nil
+			}
+		} else if err != nil {
+			return fmt.Errorf("failed to execute bulk write: %v", err)
+		}
+
+		for k, p := range pending {
+			if !failed[k] {
+				written = append(written, p.change)
+			}
+		}
+		writes, pending = nil, nil
+		clear(touched)
+		return nil
+	}
+
+	// add добавляет модель операции i и отмечает, как она меняет документ
+	add := func(i int, write mongo.WriteModel, change model.DocumentChange, before, after *model.MockDocument) {
+		writes = append(writes, write)
+		pending = append(pending, bulkPending{index: i, change: change, before: before, after: after})
+		trackDocument(existing, before, after)
+		for _, doc := range []*model.MockDocument{before, after} {
+			if doc != nil {
+				for _, key := range documentKeys(doc) {
+					touched[key] = true
+				}
+				for _, key := range doc.Unique {
+					touched[uniqueTouchKey+key] = true
+				}
+			}
+		}
+	}
+
+	for i, op := range ops {
+		results[i] = model.BulkItemResult{Index: op.Index, Op: op.Op}
+
+		target := op.DocumentID
+		if op.Op == model.BulkCreate {
+			if _, hasID := op.Data["id"]; !hasID {
+				op.Data["id"] = nextID
+				nextID++
+			}
+			target = fmt.Sprint(op.Data["id"])
+		}
+		keys := uniqueKeys(op.Data, unique)
+		// Операции пакета выполняются по порядку: документ, созданный, измененный или
+		// удаленный предыдущей операцией, должен быть записан до следующей. Так же
+		// значение уникального поля, освобожденное удалением, занимается после него
+		if touched[target] || slices.ContainsFunc(keys, func(key string) bool { return touched[uniqueTouchKey+key] }) {
+			if err := flush(); err != nil {
+				r.recordChanges(projectID, collectionName, source, written)
+				return nil, err
+			}
+		}
+
+		if op.Op == model.BulkCreate {
+			doc := &model.MockDocument{
+				ID:             bson.NewObjectID(),
+				ProjectID:      projectID,
+				CollectionName: collectionName,
+				Data:           op.Data,
+				Version:        1,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+				Unique:         keys,
+			}
+			add(i, mongo.NewInsertOneModel().SetDocument(doc), newChange(model.ChangeCreate, nil, doc), nil, doc)
+
+			results[i].Status = http.StatusCreated
+			results[i].ID = op.Data["id"]
+			results[i].Document = doc.ToClean()
+			continue
+		}
+
+		results[i].ID = op.ID
+		filter, err := documentIDFilter(projectID, op.DocumentID)
+		if err != nil {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = err.Error()
+			continue
+		}
+		before := existing[op.DocumentID]
+		if before == nil {
+			results[i].Status = http.StatusNotFound
+			results[i].Error = "document not found"
+			continue
+		}
+
+		if op.Op == model.BulkUpdate {
+			if numID, err := strconv.Atoi(op.DocumentID); err == nil {
+				op.Data["id"] = numID
+			}
+			update := dataUpdate(op.Data, unique, now)
+			results[i].Document = model.CleanDocument(op.Data)
+
+			after := *before
+			after.Data = op.Data
+			after.Version++
+			after.UpdatedAt = now
+			after.Unique = keys
+			add(i, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update), newChange(model.ChangeUpdate, before, &after), before, &after)
+		} else {
+			add(i, mongo.NewDeleteOneModel().SetFilter(filter), newChange(model.ChangeDelete, before, nil), before, nil)
+			deletes = true
+		}
+		results[i].Status = http.StatusOK
+	}
+
+	err = flush()
+	r.recordChanges(projectID, collectionName, source, written)
+	if err != nil {
+		return nil, err
+	}
+
+	// Как и при одиночном удалении, сбрасываем счетчик опустевшей коллекции
+	if deletes {
+		count, err := r.CountDocuments(projectID, collectionName)
+		if err == nil && count == 0 {
+			if err := r.ResetCounter(projectID, collectionName); err != nil {
+				slog.Warn("Failed to reset counter",
+					slog.String("collection", collectionName),
+					slog.String("error", err.Error()))
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// existingDocuments возвращает существующие документы, на которые ссылаются операции
+// update и delete, по ID из операций (data.id или ObjectID)
+func (r *DocumentRepository) existingDocuments(projectID int64, collectionName string, ops []model.BulkOperation) (map[string]*model.MockDocument, error) {
+	existing := make(map[string]*model.MockDocument)
+
+	var filters bson.A
+	for _, op := range ops {
+		if op.Op == model.BulkCreate {
+			continue
+		}
+		if filter, err := documentIDFilter(projectID, op.DocumentID); err == nil {
+			filters = append(filters, filter)
+		}
+	}
+	if len(filters) == 0 {
+		return existing, nil
+	}
+
+	collection := r.GetCollection(collectionName)
+	ctx := context.Background()
+
+	cursor, err := collection.Find(ctx, bson.M{"$or": filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var documents []*model.MockDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("failed to decode documents: %v", err)
+	}
+
+	for _, doc := range documents {
+		trackDocument(existing, nil, doc)
+	}
+	return existing, nil
+}
+
+// uniqueTouchKey префикс значений уникальных полей среди ключей touched в BulkWrite
+const uniqueTouchKey = "_unique:"
+
+// bulkPending операция пакета, модель которой еще не отправлена в MongoDB
+type bulkPending struct {
+	index  int                  // Индекс операции в ops
+	change model.DocumentChange // Запись истории, если операция выполнится
+	before *model.MockDocument  // Документ до операции (nil для create)
+	after  *model.MockDocument  // Документ после операции (nil для delete)
+}
+
+// trackDocument заменяет в existing документ before на after (nil - документа нет)
+func trackDocument(existing map[string]*model.MockDocument, before, after *model.MockDocument) {
+	if before != nil {
+		for _, key := range documentKeys(before) {
+			delete(existing, key)
+		}
+	}
+	if after != nil {
+		for _, key := range documentKeys(after) {
+			existing[key] = after
+		}
+	}
+}
+
+// documentKeys ID, по которым операции пакета находят документ: ObjectID и числовой data.id
+func documentKeys(doc *model.MockDocument) []string {
+	keys := []string{doc.ID.Hex()}
+	if id, ok := intID(doc.Data["id"]); ok {
+		keys = append(keys, strconv.Itoa(id))
+	}
+	return keys
+}
+
+// DistinctValues возвращает различные значения поля data в коллекции проекта
+func (r *DocumentRepository) DistinctValues(projectID int64, collectionName, field string) ([]any, error) {
+	collection := r.GetCollection(collectionName)
+	ctx := context.Background()
+
+	var values []any
+	err := collection.Distinct(ctx, "data."+field, bson.M{"project_id": projectID}).Decode(&values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distinct values: %v", err)
+	}
+	return values, nil
+}
+
 // CountDocuments возвращает количество документов в коллекции
 func (r *DocumentRepository) CountDocuments(projectID int64, collectionName string) (int64, error) {
 	collection := r.GetCollection(collectionName)
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestKeysetAfter(t *testing.T) {
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value any
+		desc  bool
+		want  bson.M
+		ok    bool
+	}{
+		{
+			name:  "null ascending selects any value",
+			value: nil,
+			want:  bson.M{"data.f": bson.M{"$ne": nil}},
+			ok:    true,
+		},
+		{
+			name:  "null descending is last",
+			value: nil,
+			desc:  true,
+			ok:    false,
+		},
+		{
+			name:  "number ascending continues with later types",
+			value: int64(5),
+			want: bson.M{"$or": bson.A{
+				bson.M{"data.f": bson.M{"$gt": int64(5)}},
+				bson.M{"data.f": bson.M{"$type": bson.A{"string", "symbol", "object", "array", "binData", "objectId", "bool", "date", "timestamp", "regex"}}},
+			}},
+			ok: true,
+		},
+		{
+			name:  "number descending continues with nulls only",
+			value: 5.5,
+			desc:  true,
+			want: bson.M{"$or": bson.A{
+				bson.M{"data.f": bson.M{"$lt": 5.5}},
+				bson.M{"data.f": nil},
+			}},
+			ok: true,
+		},
+		{
+			name:  "string descending continues with numbers and nulls",
+			value: "b",
+			desc:  true,
+			want: bson.M{"$or": bson.A{
+				bson.M{"data.f": bson.M{"$lt": "b"}},
+				bson.M{"data.f": bson.M{"$type": bson.A{"double", "int", "long", "decimal"}}},
+				bson.M{"data.f": nil},
+			}},
+			ok: true,
+		},
+		{
+			name:  "bool ascending continues with dates",
+			value: false,
+			want: bson.M{"$or": bson.A{
+				bson.M{"data.f": bson.M{"$gt": false}},
+				bson.M{"data.f": bson.M{"$type": bson.A{"date", "timestamp", "regex"}}},
+			}},
+			ok: true,
+		},
+		{
+			name:  "date from cursor",
+			value: bson.NewDateTimeFromTime(created),
+			want: bson.M{"$or": bson.A{
+				bson.M{"data.f": bson.M{"$gt": bson.NewDateTimeFromTime(created)}},
+				bson.M{"data.f": bson.M{"$type": bson.A{"timestamp", "regex"}}},
+			}},
+			ok: true,
+		},
+		{
+			name:  "regex ascending is the last type",
+			value: bson.Regex{Pattern: "a"},
+			want: bson.M{"$or": bson.A{
+				bson.M{"data.f": bson.M{"$gt": bson.Regex{Pattern: "a"}}},
+			}},
+			ok: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := keysetAfter("data.f", tt.value, tt.desc)
+			if ok != tt.ok {
+				t.Fatalf("keysetAfter() ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keysetAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name  string
+		keys  []model.SortKey
+		after []any
+		want  bson.M
+	}{
+		{
+			name:  "later keys require equal earlier keys",
+			keys:  []model.SortKey{{Field: model.MetaCreatedAt, Desc: true}, {Field: "id"}},
+			after: []any{nil, "a"},
+			want: bson.M{"$or": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{model.MetaCreatedAt: nil},
+					bson.M{"$or": bson.A{
+						bson.M{"data.id": bson.M{"$gt": "a"}},
+						bson.M{"data.id": bson.M{"$type": bson.A{"object", "array", "binData", "objectId", "bool", "date", "timestamp", "regex"}}},
+					}},
+				}},
+			}},
+		},
+		{
+			name:  "nothing after null in descending order",
+			keys:  []model.SortKey{{Field: "id", Desc: true}},
+			after: []any{nil},
+			want:  bson.M{"_id": bson.M{"$exists": false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keysetCondition(tt.keys, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keysetCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// historyCollection служебная коллекция истории изменений документов
+const historyCollection = models.ReservedCollectionPrefix + "document_history"
+
+func (r *DocumentRepository) history() *mongo.Collection {
+	return r.client.Database(r.dbName).Collection(historyCollection)
+}
+
+// InitHistoryIndexes создает индекс истории документа и TTL-индекс по expires_at
+func (r *DocumentRepository) InitHistoryIndexes() error {
+	_, err := r.history().Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "project_id", Value: 1},
+				{Key: "collection_name", Value: 1},
+				{Key: "document_id", Value: 1},
+				{Key: "timestamp", Value: -1},
+			},
+			Options: options.Index().
+				SetName("document_history"),
+		},
+		{
+			Keys: bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().
+				SetName("document_history_ttl").
+				SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create history indexes: %v", err)
+	}
+	return nil
+}
+
+// newChange формирует запись истории по состоянию документа до и после изменения
+func newChange(op string, before, after *model.MockDocument) model.DocumentChange {
+	change := model.DocumentChange{Op: op}
+	if before != nil {
+		change.DocumentID = documentKey(before)
+		change.Version = before.Version
+		change.Before = before.ToClean()
+	}
+	if after != nil {
+		change.DocumentID = documentKey(after)
+		change.Version = after.Version
+		change.After = after.ToClean()
+		// Время изменения совпадает с updated_at документа (Last-Modified)
+		change.Timestamp = after.UpdatedAt
+	}
+	return change
+}
+
+// documentKey ID документа для истории: data.id в строковом виде или ObjectID
+func documentKey(doc *model.MockDocument) string {
+	if id, ok := doc.Data["id"]; ok && id != nil {
+		return fmt.Sprint(id)
+	}
+	return doc.ID.Hex()
+}
+
+// ChangeListener получает изменения документов после их записи в историю
+type ChangeListener func(set model.ChangeSet)
+
+// OnChange подписывает listener на изменения документов. Подписка выполняется при запуске
+// сервиса; слушатель вызывается синхронно и долгую обработку выполняет в фоне
+func (r *DocumentRepository) OnChange(listener ChangeListener) {
+	r.listeners = append(r.listeners, listener)
+}
+
+// recordChanges сохраняет записи истории изменений одной операции
+func (r *DocumentRepository) recordChanges(projectID int64, collectionName, source string, changes []model.DocumentChange) {
+	r.record(model.ChangeSet{
+		ProjectID:      projectID,
+		CollectionName: collectionName,
+		Source:         source,
+		Changes:        changes,
+	})
+}
+
+// record сохраняет записи истории и уведомляет слушателей. Ошибка записи истории
+// не отменяет изменение документа и только логируется
+func (r *DocumentRepository) record(set model.ChangeSet) {
+	if len(set.Changes) == 0 {
+		return
+	}
+
+	if err := r.insertHistory(context.Background(), set); err != nil {
+		slog.Warn("failed to record document history",
+			slog.Int64("project_id", set.ProjectID),
+			slog.String("collection", set.CollectionName),
+			slog.String("error", err.Error()))
+	}
+	r.notify(set)
+}
+
+// insertHistory сохраняет записи истории изменений. Время изменения, если оно не
+// задано, - момент записи
+func (r *DocumentRepository) insertHistory(ctx context.Context, set model.ChangeSet) error {
+	if len(set.Changes) == 0 {
+		return nil
+	}
+	changes := set.Changes
+
+	now := time.Now()
+	var expiresAt *time.Time
+	if r.historyTTL > 0 {
+		t := now.Add(r.historyTTL)
+		expiresAt = &t
+	}
+
+	records := make([]any, len(changes))
+	for i := range changes {
+		change := &changes[i]
+		change.ProjectID = set.ProjectID
+		change.CollectionName = set.CollectionName
+		change.Source = set.Source
+		change.ExpiresAt = expiresAt
+		if change.Timestamp.IsZero() {
+			change.Timestamp = now
+		}
+		records[i] = change
+	}
+
+	if _, err := r.history().InsertMany(ctx, records); err != nil {
+		return fmt.Errorf("failed to insert history: %v", err)
+	}
+	return nil
+}
+
+// notify передает изменения слушателям
+func (r *DocumentRepository) notify(set model.ChangeSet) {
+	for _, listener := range r.listeners {
+		listener(set)
+	}
+}
+
+// GetHistory возвращает изменения документа, новые первыми
+func (r *DocumentRepository) GetHistory(projectID int64, collectionName, documentID string, opts model.QueryOptions) ([]model.DocumentChange, error) {
+	ctx := context.Background()
+
+	filter := bson.M{
+		"project_id":      projectID,
+		"collection_name": collectionName,
+		"document_id":     documentID,
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}})
+	if opts.Limit != nil {
+		findOptions.SetLimit(*opts.Limit)
+	}
+	if opts.Offset != nil {
+		findOptions.SetSkip(*opts.Offset)
+	}
+
+	cursor, err := r.history().Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find history: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	changes := []model.DocumentChange{}
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, fmt.Errorf("failed to decode history: %v", err)
+	}
+	return changes, nil
+}
+
+// GetDocumentAsOf восстанавливает документ на момент asOf по истории изменений.
+// Возвращает nil, если документа тогда не было (или его изменения не попали в историю)
+func (r *DocumentRepository) GetDocumentAsOf(projectID int64, collectionName, documentID string, asOf time.Time) (*model.MockDocument, error) {
+	filter := bson.M{
+		"project_id":      projectID,
+		"collection_name": collectionName,
+		"document_id":     documentID,
+		"timestamp":       bson.M{"$lte": asOf},
+	}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}})
+
+	var change model.DocumentChange
+	err := r.history().FindOne(context.Background(), filter, findOptions).Decode(&change)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find history: %v", err)
+	}
+	return changeDocument(&change), nil
+}
+
+// GetDocumentsAsOf восстанавливает документы коллекции на момент asOf по истории изменений:
+// для каждого документа берется последнее изменение до asOf, удаленные пропускаются.
+// Документы идут в порядке создания, новые первыми; применяются только limit и offset
+func (r *DocumentRepository) GetDocumentsAsOf(projectID int64, collectionName string, asOf time.Time, opts model.QueryOptions) (*model.DocumentsResponse, error) {
+	ctx := context.Background()
+
+	page := bson.A{bson.M{"$skip": getInt64Value(opts.Offset, 0)}}
+	if opts.Limit != nil && *opts.Limit > 0 {
+		page = append(page, bson.M{"$limit": *opts.Limit})
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"project_id":      projectID,
+			"collection_name": collectionName,
+			"timestamp":       bson.M{"$lte": asOf},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$document_id",
+			"last":       bson.M{"$last": "$$ROOT"},
+			"created_at": bson.M{"$first": "$timestamp"},
+		}}},
+		{{Key: "$match", Value: bson.M{"last.op": bson.M{"$ne": model.ChangeDelete}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "n"}},
+			"page":  page,
+		}}},
+	}
+
+	cursor, err := r.history().Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate history: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Total []struct {
+			N int64 `bson:"n"`
+		} `bson:"total"`
+		Page []struct {
+			Last      model.DocumentChange `bson:"last"`
+			CreatedAt time.Time            `bson:"created_at"`
+		} `bson:"page"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode history: %v", err)
+	}
+
+	response := &model.DocumentsResponse{
+		Documents: []*model.MockDocument{},
+		Limit:     getInt64Value(opts.Limit, 0),
+		Offset:    getInt64Value(opts.Offset, 0),
+	}
+	if len(result) == 0 {
+		return response, nil
+	}
+	if len(result[0].Total) > 0 {
+		response.Total = result[0].Total[0].N
+	}
+	for _, item := range result[0].Page {
+		doc := changeDocument(&item.Last)
+		doc.CreatedAt = item.CreatedAt
+		response.Documents = append(response.Documents, doc)
+	}
+	return response, nil
+}
+
+// changeDocument возвращает документ в состоянии после изменения или nil для удаления
+func changeDocument(change *model.DocumentChange) *model.MockDocument {
+	if change.Op == model.ChangeDelete {
+		return nil
+	}
+	return &model.MockDocument{
+		ProjectID:      change.ProjectID,
+		CollectionName: change.CollectionName,
+		Data:           map[string]any(change.After),
+		Version:        change.Version,
+		UpdatedAt:      change.Timestamp,
+	}
+}
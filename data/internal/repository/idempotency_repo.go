@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// idempotencyCollection служебная коллекция ключей идемпотентности
+const idempotencyCollection = models.ReservedCollectionPrefix + "idempotency_keys"
+
+type IdempotencyRepository struct {
+	client *mongo.Client
+	dbName string
+}
+
+func NewIdempotencyRepository(client *mongo.Client, dbName string) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		client: client,
+		dbName: dbName,
+	}
+}
+
+func (r *IdempotencyRepository) collection() *mongo.Collection {
+	return r.client.Database(r.dbName).Collection(idempotencyCollection)
+}
+
+// InitIndexes создает уникальный индекс ключей проекта и TTL-индекс по expires_at
+func (r *IdempotencyRepository) InitIndexes() error {
+	_, err := r.collection().Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().
+				SetName("idempotency_key").
+				SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().
+				SetName("idempotency_ttl").
+				SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency indexes: %v", err)
+	}
+	return nil
+}
+
+// Reserve сохраняет ключ для выполняемого запроса. Если ключ уже использован,
+// возвращает существующую запись. Истекшая, но еще не удаленная TTL-индексом
+// запись заменяется новой
+func (r *IdempotencyRepository) Reserve(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	ctx := context.Background()
+
+	_, err := r.collection().InsertOne(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %v", err)
+	}
+
+	filter := bson.M{"project_id": record.ProjectID, "key": record.Key}
+
+	var existing model.IdempotencyRecord
+	if err := r.collection().FindOne(ctx, filter).Decode(&existing); err != nil {
+		return nil, fmt.Errorf("failed to find idempotency key: %v", err)
+	}
+	if existing.ExpiresAt.After(time.Now()) {
+		return &existing, nil
+	}
+
+	filter["expires_at"] = existing.ExpiresAt
+	result, err := r.collection().ReplaceOne(ctx, filter, record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		// Ключ параллельно занял другой запрос
+		return r.Reserve(record)
+	}
+	return nil, nil
+}
+
+// Complete сохраняет ответ на запрос для повторов и продлевает срок хранения ключа
+func (r *IdempotencyRepository) Complete(record *model.IdempotencyRecord) error {
+	filter := bson.M{"project_id": record.ProjectID, "key": record.Key}
+	update := bson.M{"$set": bson.M{
+		"status":     record.Status,
+		"headers":    record.Headers,
+		"body":       record.Body,
+		"truncated":  record.Truncated,
+		"expires_at": record.ExpiresAt,
+	}}
+
+	if _, err := r.collection().UpdateOne(context.Background(), filter, update); err != nil {
+		return fmt.Errorf("failed to save idempotent response: %v", err)
+	}
+	return nil
+}
+
+// Release удаляет ключ, чтобы запрос можно было повторить
+func (r *IdempotencyRepository) Release(projectID int64, key string) error {
+	filter := bson.M{"project_id": projectID, "key": key}
+	if _, err := r.collection().DeleteOne(context.Background(), filter); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Служебные коллекции хранятся в одной базе с коллекциями проектов. Их имена начинаются
+// с models.ReservedCollectionPrefix, который запрещен в именах коллекций проектов,
+// поэтому запросы к коллекциям проекта их не затрагивают
+
+// countersCollection служебная коллекция счетчиков автоинкремента
+const countersCollection = models.ReservedCollectionPrefix + "counters"
+
+// legacyCollections прежние имена служебных коллекций, совпадавшие с допустимыми
+// именами коллекций проектов
+var legacyCollections = map[string]string{
+	"counters":           countersCollection,
+	"document_history":   historyCollection,
+	"idempotency_keys":   idempotencyCollection,
+	"snapshots":          snapshotsCollection,
+	"snapshot_documents": snapshotDocumentsCollection,
+	"webhook_deliveries": deliveriesCollection,
+}
+
+// MigrateInternalCollections переносит служебные записи из коллекций с прежними именами.
+// Документы коллекции проекта с тем же именем (с data и collection_name, равным имени
+// коллекции) остаются на месте. Повторный запуск переносит только оставшиеся записи
+func MigrateInternalCollections(client *mongo.Client, dbName string) error {
+	ctx := context.Background()
+	db := client.Database(dbName)
+
+	for legacy, name := range legacyCollections {
+		internal := bson.M{"$nor": bson.A{bson.M{
+			"data":            bson.M{"$exists": true},
+			"collection_name": legacy,
+		}}}
+
+		found, err := db.Collection(legacy).CountDocuments(ctx, internal)
+		if err != nil {
+			return fmt.Errorf("failed to count %s: %v", legacy, err)
+		}
+		if found == 0 {
+			continue
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: internal}},
+			{{Key: "$merge", Value: bson.M{"into": name, "whenMatched": "keepExisting"}}},
+		}
+		cursor, err := db.Collection(legacy).Aggregate(ctx, pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %v", legacy, err)
+		}
+		cursor.Close(ctx)
+
+		if _, err := db.Collection(legacy).DeleteMany(ctx, internal); err != nil {
+			return fmt.Errorf("failed to migrate %s: %v", legacy, err)
+		}
+		slog.Info("Migrated internal collection",
+			slog.String("from", legacy),
+			slog.String("to", name),
+			slog.Int64("count", found))
+	}
+	return nil
+}
@@ -0,0 +1,476 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrSnapshotExists снимок с таким именем у проекта уже есть
+var ErrSnapshotExists = errors.New("snapshot already exists")
+
+// ErrTransactionsUnavailable MongoDB запущена без replica set и не поддерживает транзакции
+var ErrTransactionsUnavailable = errors.New("transactions are not supported")
+
+// Служебные коллекции снимков: описания снимков и копии документов
+const (
+	snapshotsCollection         = models.ReservedCollectionPrefix + "snapshots"
+	snapshotDocumentsCollection = models.ReservedCollectionPrefix + "snapshot_documents"
+)
+
+// restoreBatchSize количество документов, вставляемых за одно обращение при восстановлении
+const restoreBatchSize = 500
+
+type SnapshotRepository struct {
+	client *mongo.Client
+	dbName string
+	docs   *DocumentRepository // История изменений и слушатели изменений документов
+	// Разрешить восстановление без транзакций, если MongoDB их не поддерживает
+	restoreWithoutTransaction bool
+}
+
+func NewSnapshotRepository(client *mongo.Client, dbName string, docs *DocumentRepository, restoreWithoutTransaction bool) *SnapshotRepository {
+	return &SnapshotRepository{
+		client:                    client,
+		dbName:                    dbName,
+		docs:                      docs,
+		restoreWithoutTransaction: restoreWithoutTransaction,
+	}
+}
+
+func (r *SnapshotRepository) collection(name string) *mongo.Collection {
+	return r.client.Database(r.dbName).Collection(name)
+}
+
+// snapshotDocument копия документа коллекции в снимке
+type snapshotDocument struct {
+	SnapshotID     bson.ObjectID  `bson:"snapshot_id"`
+	DocID          bson.ObjectID  `bson:"doc_id"`
+	ProjectID      int64          `bson:"project_id"`
+	CollectionName string         `bson:"collection_name"`
+	Data           map[string]any `bson:"data"`
+	Version        int64          `bson:"version"`
+	CreatedAt      time.Time      `bson:"created_at"`
+	UpdatedAt      time.Time      `bson:"updated_at"`
+	Unique         []string       `bson:"_unique,omitempty"`
+}
+
+// InitIndexes создает уникальный индекс имен снимков проекта и индекс копий документов
+func (r *SnapshotRepository) InitIndexes() error {
+	ctx := context.Background()
+
+	_, err := r.collection(snapshotsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "snapshot_name", Value: 1}},
+		Options: options.Index().
+			SetName("snapshot_name").
+			SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot indexes: %v", err)
+	}
+
+	_, err = r.collection(snapshotDocumentsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "snapshot_id", Value: 1}, {Key: "collection_name", Value: 1}},
+		Options: options.Index().
+			SetName("snapshot_documents"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot indexes: %v", err)
+	}
+	return nil
+}
+
+// counters возвращает значения счетчиков автоинкремента коллекций проекта
+func (r *SnapshotRepository) counters(ctx context.Context, projectID int64) (map[string]int, error) {
+	cursor, err := r.collection(countersCollection).Find(ctx, bson.M{"project_id": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find counters: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var counters []struct {
+		CollectionName string `bson:"collection_name"`
+		Seq            int    `bson:"seq"`
+	}
+	if err := cursor.All(ctx, &counters); err != nil {
+		return nil, fmt.Errorf("failed to decode counters: %v", err)
+	}
+
+	result := make(map[string]int, len(counters))
+	for _, counter := range counters {
+		result[counter.CollectionName] = counter.Seq
+	}
+	return result, nil
+}
+
+// Create копирует документы коллекций проекта names и счетчики автоинкремента в снимок.
+// Копирование выполняется на стороне MongoDB ($merge), документы не проходят через сервис
+func (r *SnapshotRepository) Create(projectID int64, name string, names []string) (*model.Snapshot, error) {
+	ctx := context.Background()
+
+	existing, err := r.Get(projectID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrSnapshotExists
+	}
+
+	counters, err := r.counters(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &model.Snapshot{
+		ID:          bson.NewObjectID(),
+		ProjectID:   projectID,
+		Name:        name,
+		Collections: []model.SnapshotCollection{},
+		CreatedAt:   time.Now(),
+	}
+
+	for _, collectionName := range names {
+		count, err := r.copyCollection(ctx, snapshot.ID, projectID, collectionName)
+		if err != nil {
+			r.deleteDocuments(snapshot.ID)
+			return nil, err
+		}
+		snapshot.Collections = append(snapshot.Collections, model.SnapshotCollection{
+			Name:      collectionName,
+			Documents: count,
+			Counter:   counters[collectionName],
+		})
+	}
+
+	// Описание снимка сохраняется последним: снимок без описания не виден и не восстанавливается
+	if _, err := r.collection(snapshotsCollection).InsertOne(ctx, snapshot); err != nil {
+		r.deleteDocuments(snapshot.ID)
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSnapshotExists
+		}
+		return nil, fmt.Errorf("failed to save snapshot: %v", err)
+	}
+	return snapshot, nil
+}
+
+// copyCollection копирует документы коллекции проекта в снимок и возвращает их количество
+func (r *SnapshotRepository) copyCollection(ctx context.Context, snapshotID bson.ObjectID, projectID int64, collectionName string) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"project_id": projectID, "collection_name": collectionName}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":             0,
+			"snapshot_id":     bson.M{"$literal": snapshotID},
+			"doc_id":          "$_id",
+			"project_id":      1,
+			"collection_name": 1,
+			"data":            1,
+			"version":         1,
+			"created_at":      1,
+			"updated_at":      1,
+			"_unique":         1,
+		}}},
+		{{Key: "$merge", Value: bson.M{"into": snapshotDocumentsCollection}}},
+	}
+
+	cursor, err := r.collection(collectionName).Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy collection %s: %v", collectionName, err)
+	}
+	cursor.Close(ctx)
+
+	count, err := r.collection(snapshotDocumentsCollection).CountDocuments(ctx, bson.M{
+		"snapshot_id":     snapshotID,
+		"collection_name": collectionName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count snapshot documents: %v", err)
+	}
+	return count, nil
+}
+
+// List возвращает снимки проекта, новые первыми
+func (r *SnapshotRepository) List(projectID int64) ([]model.Snapshot, error) {
+	ctx := context.Background()
+
+	filter := bson.M{"project_id": projectID}
+	cursor, err := r.collection(snapshotsCollection).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find snapshots: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	snapshots := []model.Snapshot{}
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshots: %v", err)
+	}
+	return snapshots, nil
+}
+
+// Get возвращает снимок проекта по имени или nil, если его нет
+func (r *SnapshotRepository) Get(projectID int64, name string) (*model.Snapshot, error) {
+	var snapshot model.Snapshot
+	filter := bson.M{"project_id": projectID, "snapshot_name": name}
+	err := r.collection(snapshotsCollection).FindOne(context.Background(), filter).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find snapshot: %v", err)
+	}
+	return &snapshot, nil
+}
+
+// Delete удаляет снимок с копиями документов. Возвращает false, если снимка нет
+func (r *SnapshotRepository) Delete(projectID int64, name string) (bool, error) {
+	snapshot, err := r.Get(projectID, name)
+	if err != nil || snapshot == nil {
+		return false, err
+	}
+
+	if _, err := r.collection(snapshotsCollection).DeleteOne(context.Background(), bson.M{"_id": snapshot.ID}); err != nil {
+		return false, fmt.Errorf("failed to delete snapshot: %v", err)
+	}
+	if err := r.deleteDocuments(snapshot.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *SnapshotRepository) deleteDocuments(snapshotID bson.ObjectID) error {
+	_, err := r.collection(snapshotDocumentsCollection).DeleteMany(context.Background(), bson.M{"snapshot_id": snapshotID})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot documents: %v", err)
+	}
+	return nil
+}
+
+// Restore заменяет документы и счетчики коллекций проекта содержимым снимка.
+// Коллекции current, которых не было в снимке, очищаются.
+//
+// Коллекции восстанавливаются по одной: документы удаляются и вставляются порциями
+// по restoreBatchSize, каждая порция вместе с записями истории - в своей транзакции,
+// поэтому размер и длительность транзакции не зависят от размера коллекции.
+// Восстановление в целом не атомарно: пока оно идет, коллекция может быть
+// восстановлена частично. Без поддержки транзакций (MongoDB без replica set)
+// возвращается ErrTransactionsUnavailable, если восстановление без транзакций
+// не разрешено restoreWithoutTransaction. Слушатели получают очистку (Flush)
+// каждой восстановленной коллекции
+func (r *SnapshotRepository) Restore(snapshot *model.Snapshot, current []string) error {
+	ctx := context.Background()
+
+	counters := map[string]int{}
+	for _, name := range current {
+		counters[name] = 0
+	}
+	restored := map[string]bool{}
+	for _, c := range snapshot.Collections {
+		counters[c.Name] = c.Counter
+		restored[c.Name] = true
+	}
+	names := make([]string, 0, len(counters))
+	for name := range counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %v", err)
+	}
+	defer session.EndSession(ctx)
+	batch := &restoreBatch{repo: r, session: session, snapshot: snapshot, transactions: true}
+
+	for _, collectionName := range names {
+		if err := r.clearCollection(ctx, batch, snapshot.ProjectID, collectionName); err != nil {
+			return err
+		}
+		if restored[collectionName] {
+			if err := r.restoreCollection(ctx, batch, snapshot.ID, snapshot.ProjectID, collectionName); err != nil {
+				return err
+			}
+		}
+		if err := r.restoreCounter(ctx, snapshot.ProjectID, collectionName, counters[collectionName]); err != nil {
+			return err
+		}
+
+		r.docs.notify(model.ChangeSet{
+			ProjectID:      snapshot.ProjectID,
+			CollectionName: collectionName,
+			Source:         model.ChangeSourceSnapshot,
+			Flush:          true,
+		})
+	}
+	return nil
+}
+
+// restoreBatch выполняет порции восстановления снимка в транзакциях
+type restoreBatch struct {
+	repo         *SnapshotRepository
+	session      *mongo.Session
+	snapshot     *model.Snapshot
+	transactions bool // false - MongoDB не поддерживает транзакции
+}
+
+// run выполняет fn в транзакции. Если MongoDB не поддерживает транзакции, fn и следующие
+// порции выполняются без них при разрешенном restoreWithoutTransaction, иначе
+// возвращается ErrTransactionsUnavailable
+func (b *restoreBatch) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !b.transactions {
+		return fn(ctx)
+	}
+	_, err := b.session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, fn(ctx)
+	})
+	if !transactionsUnsupported(err) {
+		return err
+	}
+	if !b.repo.restoreWithoutTransaction {
+		return ErrTransactionsUnavailable
+	}
+	slog.Warn("MongoDB does not support transactions, restoring snapshot without them",
+		slog.Int64("project_id", b.snapshot.ProjectID),
+		slog.String("snapshot", b.snapshot.Name))
+	b.transactions = false
+	return fn(ctx)
+}
+
+// transactionsUnsupported проверяет ошибку транзакции на standalone-сервере (IllegalOperation)
+func transactionsUnsupported(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(20)
+}
+
+// restoreCounter выставляет счетчик автоинкремента коллекции
+func (r *SnapshotRepository) restoreCounter(ctx context.Context, projectID int64, collectionName string, seq int) error {
+	filter := bson.M{"project_id": projectID, "collection_name": collectionName}
+	update := bson.M{"$set": bson.M{"seq": seq}}
+	if _, err := r.collection(countersCollection).UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to restore counter of %s: %v", collectionName, err)
+	}
+	return nil
+}
+
+// clearCollection удаляет документы коллекции проекта порциями по restoreBatchSize,
+// записывая удаление каждого документа в историю
+func (r *SnapshotRepository) clearCollection(ctx context.Context, batch *restoreBatch, projectID int64, collectionName string) error {
+	target := r.collection(collectionName)
+	filter := bson.M{"project_id": projectID, "collection_name": collectionName}
+
+	for {
+		var docs []model.MockDocument
+		err := batch.run(ctx, func(ctx context.Context) error {
+			cursor, err := target.Find(ctx, filter, options.Find().SetLimit(restoreBatchSize))
+			if err != nil {
+				return fmt.Errorf("failed to read collection %s: %v", collectionName, err)
+			}
+			docs = docs[:0]
+			if err := cursor.All(ctx, &docs); err != nil {
+				return fmt.Errorf("failed to read collection %s: %v", collectionName, err)
+			}
+			if len(docs) == 0 {
+				return nil
+			}
+
+			ids := make(bson.A, len(docs))
+			changes := make([]model.DocumentChange, len(docs))
+			for i := range docs {
+				ids[i] = docs[i].ID
+				changes[i] = newChange(model.ChangeDelete, &docs[i], nil)
+			}
+			err = r.docs.insertHistory(ctx, model.ChangeSet{
+				ProjectID:      projectID,
+				CollectionName: collectionName,
+				Source:         model.ChangeSourceSnapshot,
+				Changes:        changes,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := target.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+				return fmt.Errorf("failed to clear collection %s: %v", collectionName, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(docs) < restoreBatchSize {
+			return nil
+		}
+	}
+}
+
+// restoreCollection вставляет копии документов коллекции из снимка порциями
+// и записывает их создание в историю
+func (r *SnapshotRepository) restoreCollection(ctx context.Context, batch *restoreBatch, snapshotID bson.ObjectID, projectID int64, collectionName string) error {
+	filter := bson.M{"snapshot_id": snapshotID, "collection_name": collectionName}
+	cursor, err := r.collection(snapshotDocumentsCollection).Find(ctx, filter, options.Find().SetBatchSize(restoreBatchSize))
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot documents: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	target := r.collection(collectionName)
+	docs := make([]any, 0, restoreBatchSize)
+	changes := make([]model.DocumentChange, 0, restoreBatchSize)
+	insert := func() error {
+		if len(docs) == 0 {
+			return nil
+		}
+		err := batch.run(ctx, func(ctx context.Context) error {
+			if _, err := target.InsertMany(ctx, docs); err != nil {
+				return fmt.Errorf("failed to restore collection %s: %v", collectionName, err)
+			}
+			return r.docs.insertHistory(ctx, model.ChangeSet{
+				ProjectID:      projectID,
+				CollectionName: collectionName,
+				Source:         model.ChangeSourceSnapshot,
+				Changes:        changes,
+			})
+		})
+		docs = docs[:0]
+		changes = changes[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var doc snapshotDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode snapshot document: %v", err)
+		}
+		restored := &model.MockDocument{
+			ID:             doc.DocID,
+			ProjectID:      doc.ProjectID,
+			CollectionName: doc.CollectionName,
+			Data:           doc.Data,
+			Version:        doc.Version,
+			CreatedAt:      doc.CreatedAt,
+			UpdatedAt:      doc.UpdatedAt,
+			Unique:         doc.Unique,
+		}
+		docs = append(docs, restored)
+		// Документ появляется в момент восстановления, а не в updated_at из снимка:
+		// иначе as_of после восстановления видел бы его удаленным
+		change := newChange(model.ChangeCreate, nil, restored)
+		change.Timestamp = time.Now()
+		changes = append(changes, change)
+		if len(docs) == restoreBatchSize {
+			if err := insert(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read snapshot documents: %v", err)
+	}
+	return insert()
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Уникальность полей схемы гарантирует один уникальный multikey-индекс коллекции
+// MongoDB по (project_id, _unique): документ хранит в _unique значения своих уникальных
+// полей в виде "field=value". Индекс общий для всех проектов и полей, поэтому число
+// индексов коллекции не растет вместе с числом проектов
+const uniqueIndexName = "unique_values"
+
+// uniqueKeyPattern извлекает имя поля из ошибки дубликата ключа уникального индекса
+var uniqueKeyPattern = regexp.MustCompile(`_unique: "([^"=]+)=`)
+
+// UniqueValue возвращает значение уникального поля документа. Отсутствующие поля,
+// null и массивы уникальность не нарушают
+func UniqueValue(data map[string]any, field string) (any, bool) {
+	value, ok := data[field]
+	if !ok || value == nil {
+		return nil, false
+	}
+	if _, isArray := value.([]any); isArray {
+		return nil, false
+	}
+	return value, true
+}
+
+// UniqueKey значение уникального поля в индексе _unique. Тип значения входит в ключ,
+// как и при сравнении в MongoDB: 1 и "1" различны, 1 и 1.0 совпадают
+func UniqueKey(field string, value any) string {
+	var key string
+	switch v := value.(type) {
+	case string:
+		key = "s:" + v
+	case bool:
+		key = "b:" + strconv.FormatBool(v)
+	case float64:
+		key = "n:" + strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		key = "n:" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case int:
+		key = "n:" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case int32:
+		key = "n:" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case int64:
+		key = "n:" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case time.Time:
+		key = "d:" + v.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano)
+	case bson.DateTime:
+		key = "d:" + v.Time().UTC().Format(time.RFC3339Nano)
+	default:
+		key = "o:" + fmt.Sprint(v)
+	}
+	return field + "=" + key
+}
+
+// uniqueKeys значения уникальных полей fields документа для _unique
+func uniqueKeys(data map[string]any, fields []string) []string {
+	var keys []string
+	for _, field := range fields {
+		if value, ok := UniqueValue(data, field); ok {
+			keys = append(keys, UniqueKey(field, value))
+		}
+	}
+	return keys
+}
+
+// dataUpdate обновление data документа вместе со значениями уникальных полей
+func dataUpdate(data map[string]any, fields []string, updatedAt time.Time) bson.M {
+	set := bson.M{"data": data, "updated_at": updatedAt}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	// Пустой массив попал бы в индекс как отдельное значение, поэтому поле удаляется
+	if keys := uniqueKeys(data, fields); len(keys) > 0 {
+		set["_unique"] = keys
+	} else {
+		update["$unset"] = bson.M{"_unique": ""}
+	}
+	return update
+}
+
+// ensureUniqueIndex создает уникальный индекс _unique в коллекции, если в схеме есть
+// уникальные поля. Индекс создается один раз на коллекцию MongoDB за время работы сервиса
+func (r *DocumentRepository) ensureUniqueIndex(collectionName string, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	if _, ok := r.uniqueIndexes.Load(collectionName); ok {
+		return nil
+	}
+
+	_, err := r.GetCollection(collectionName).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "_unique", Value: 1}},
+		Options: options.Index().
+			SetName(uniqueIndexName).
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"_unique": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create unique index: %v", err)
+	}
+	r.uniqueIndexes.Store(collectionName, true)
+	return nil
+}
+
+// duplicateValueError формирует ErrDuplicateValue с именем поля из ошибки дубликата ключа MongoDB
+func duplicateValueError(err error) error {
+	if match := uniqueKeyPattern.FindStringSubmatch(err.Error()); match != nil {
+		return fmt.Errorf("%w: field %q must be unique", ErrDuplicateValue, match[1])
+	}
+	return fmt.Errorf("%w: %v", ErrDuplicateValue, err)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// deliveriesCollection служебная коллекция журнала доставки вебхуков
+const deliveriesCollection = models.ReservedCollectionPrefix + "webhook_deliveries"
+
+type WebhookRepository struct {
+	client *mongo.Client
+	dbName string
+}
+
+func NewWebhookRepository(client *mongo.Client, dbName string) *WebhookRepository {
+	return &WebhookRepository{
+		client: client,
+		dbName: dbName,
+	}
+}
+
+func (r *WebhookRepository) collection() *mongo.Collection {
+	return r.client.Database(r.dbName).Collection(deliveriesCollection)
+}
+
+// InitIndexes создает индекс журнала проекта и TTL-индекс по expires_at
+func (r *WebhookRepository) InitIndexes() error {
+	_, err := r.collection().Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().
+				SetName("webhook_deliveries"),
+		},
+		{
+			Keys: bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().
+				SetName("webhook_deliveries_ttl").
+				SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery indexes: %v", err)
+	}
+	return nil
+}
+
+// CreateDeliveries сохраняет новые записи журнала доставки одним запросом
+func (r *WebhookRepository) CreateDeliveries(deliveries []*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	docs := make([]any, len(deliveries))
+	for i, delivery := range deliveries {
+		docs[i] = delivery
+	}
+
+	result, err := r.collection().InsertMany(context.Background(), docs)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %v", err)
+	}
+	for i, id := range result.InsertedIDs {
+		deliveries[i].ID = id.(bson.ObjectID)
+	}
+	return nil
+}
+
+// AddAttempt добавляет попытку доставки и обновляет статус и время следующей попытки
+func (r *WebhookRepository) AddAttempt(delivery *model.WebhookDelivery, attempt model.WebhookAttempt) error {
+	set := bson.M{
+		"delivery_status": delivery.Status,
+		"updated_at":      time.Now(),
+	}
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  set,
+	}
+	if delivery.NextAttemptAt != nil {
+		set["next_attempt_at"] = delivery.NextAttemptAt
+	} else {
+		update["$unset"] = bson.M{"next_attempt_at": ""}
+	}
+
+	_, err := r.collection().UpdateByID(context.Background(), delivery.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %v", err)
+	}
+	return nil
+}
+
+// ListDeliveries возвращает журнал доставок проекта, новые первыми.
+// webhookID 0 и пустой status не ограничивают выборку
+func (r *WebhookRepository) ListDeliveries(projectID, webhookID int64, status string, opts model.QueryOptions) ([]model.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	filter := bson.M{"project_id": projectID}
+	if webhookID != 0 {
+		filter["webhook_id"] = webhookID
+	}
+	if status != "" {
+		filter["delivery_status"] = status
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if opts.Limit != nil {
+		findOptions.SetLimit(*opts.Limit)
+	}
+	if opts.Offset != nil {
+		findOptions.SetSkip(*opts.Offset)
+	}
+
+	cursor, err := r.collection().Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []model.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook deliveries: %v", err)
+	}
+	return deliveries, nil
+}
+
+// FailPending помечает неудачными доставки, повторные попытки которых не переживают
+// перезапуск сервиса. Возвращает число таких доставок
+func (r *WebhookRepository) FailPending() (int64, error) {
+	result, err := r.collection().UpdateMany(context.Background(),
+		bson.M{"delivery_status": model.DeliveryPending},
+		bson.M{
+			"$set":   bson.M{"delivery_status": model.DeliveryFailed, "updated_at": time.Now()},
+			"$unset": bson.M{"next_attempt_at": ""},
+		})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail pending webhook deliveries: %v", err)
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// streamBuffer размер очереди событий подписчика. Подписчик, который не успевает
+// читать события, отключается: клиент переподключается и перечитывает коллекцию
+const streamBuffer = 256
+
+// ChangeEvents формирует события по изменениям одной операции. Генерация и очистка
+// коллекции дают одно событие коллекции, остальные операции - событие на каждый документ
+func ChangeEvents(set model.ChangeSet) []model.ChangeEvent {
+	base := model.ChangeEvent{
+		ID:         bson.NewObjectID().Hex(),
+		ProjectID:  set.ProjectID,
+		Collection: set.CollectionName,
+		Source:     set.Source,
+		Timestamp:  time.Now(),
+		Count:      len(set.Changes),
+	}
+	switch {
+	case set.Flush:
+		base.Event = models.WebhookCollectionFlushed
+		base.Count = set.Count
+		return []model.ChangeEvent{base}
+	case set.Source == model.ChangeSourceGenerator:
+		base.Event = models.WebhookCollectionGenerated
+		return []model.ChangeEvent{base}
+	}
+
+	events := make([]model.ChangeEvent, len(set.Changes))
+	for i, change := range set.Changes {
+		event := base
+		event.ID = bson.NewObjectID().Hex()
+		event.Timestamp = change.Timestamp
+		event.DocumentID = change.DocumentID
+		event.Version = change.Version
+		event.Data = change.After
+		event.Previous = change.Before
+		event.Count = 0
+		switch change.Op {
+		case model.ChangeCreate:
+			event.Event = models.WebhookDocumentCreated
+		case model.ChangeUpdate:
+			event.Event = models.WebhookDocumentUpdated
+		case model.ChangeDelete:
+			event.Event = models.WebhookDocumentDeleted
+		}
+		events[i] = event
+	}
+	return events
+}
+
+// StreamFilter отбор событий потока изменений коллекции
+type StreamFilter struct {
+	Events     []string               // Пусто - все события
+	Conditions []models.RuleCondition // Условия на поля документа, все должны выполняться
+}
+
+// NewStreamFilter проверяет типы событий и переводит фильтры по полям (?status=active,
+// ?price_gte=10) в условия на документ события
+func NewStreamFilter(events []string, filters []model.FieldFilter) (StreamFilter, error) {
+	for _, event := range events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return StreamFilter{}, fmt.Errorf("%w: unknown event %q, must be one of %s", ErrInvalidQuery, event, strings.Join(models.WebhookEvents, ", "))
+		}
+	}
+
+	filter := StreamFilter{Events: events}
+	for _, f := range filters {
+		if len(f.Raw) == 0 {
+			return StreamFilter{}, fmt.Errorf("%w: filter %s has no value", ErrInvalidQuery, f.Field)
+		}
+		condition := models.RuleCondition{
+			Source:   models.RuleSourceBody,
+			Field:    f.Field,
+			Operator: f.Operator,
+			Value:    f.Raw[len(f.Raw)-1],
+		}
+		switch {
+		case f.Operator == model.FilterIn, f.Operator == model.FilterEq && len(f.Raw) > 1:
+			// Несколько значений ?status=a&status=b равнозначны status_in=a,b
+			condition.Operator = model.FilterIn
+			condition.Value = strings.Join(splitList(f.Raw), ",")
+		case f.Operator == model.FilterLike:
+			if _, err := regexp.Compile(condition.Value); err != nil {
+				return StreamFilter{}, fmt.Errorf("%w: %s_like: invalid pattern %q", ErrInvalidQuery, f.Field, condition.Value)
+			}
+		}
+		filter.Conditions = append(filter.Conditions, condition)
+	}
+	return filter, nil
+}
+
+// Matches сообщает, проходит ли событие фильтр. Условия проверяются по документу после
+// изменения, для удаления - по удаленному. События коллекции фильтр по полям не отсекает:
+// после них клиенту нужно перечитать коллекцию
+func (f StreamFilter) Matches(event *model.ChangeEvent) bool {
+	if len(f.Events) > 0 && !slices.Contains(f.Events, event.Event) {
+		return false
+	}
+	if event.DocumentID == "" {
+		return true
+	}
+
+	doc := event.Data
+	if doc == nil {
+		doc = event.Previous
+	}
+	req := RequestData{Body: map[string]any(doc)}
+	for _, c := range f.Conditions {
+		if !conditionHolds(c, req) {
+			return false
+		}
+	}
+	return true
+}
+
+// ChangeFeed рассылает события изменений подписчикам потоков коллекций (SSE и WebSocket).
+// Подписки живут в памяти экземпляра сервиса
+type ChangeFeed struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription подписка на события одной коллекции проекта
+type Subscription struct {
+	projectID      int64
+	collectionName string
+	filter         StreamFilter
+	events         chan model.ChangeEvent
+}
+
+func NewChangeFeed() *ChangeFeed {
+	return &ChangeFeed{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Events канал событий подписки. Закрывается при отписке и при переполнении очереди
+func (s *Subscription) Events() <-chan model.ChangeEvent {
+	return s.events
+}
+
+// Subscribe подписывает на события коллекции, прошедшие фильтр
+func (f *ChangeFeed) Subscribe(projectID int64, collectionName string, filter StreamFilter) *Subscription {
+	sub := &Subscription{
+		projectID:      projectID,
+		collectionName: collectionName,
+		filter:         filter,
+		events:         make(chan model.ChangeEvent, streamBuffer),
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe отменяет подписку. Повторный вызов ничего не делает
+func (f *ChangeFeed) Unsubscribe(sub *Subscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.remove(sub)
+}
+
+func (f *ChangeFeed) remove(sub *Subscription) {
+	if _, ok := f.subscribers[sub]; ok {
+		delete(f.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// HandleChanges слушатель изменений DocumentRepository. Не блокирует запись:
+// событие, не помещающееся в очередь подписчика, отключает подписчика
+func (f *ChangeFeed) HandleChanges(set model.ChangeSet) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var events []model.ChangeEvent
+	for sub := range f.subscribers {
+		if sub.projectID != set.ProjectID || sub.collectionName != set.CollectionName {
+			continue
+		}
+		if events == nil {
+			events = ChangeEvents(set)
+		}
+		for i := range events {
+			if !sub.filter.Matches(&events[i]) {
+				continue
+			}
+			select {
+			case sub.events <- events[i]:
+			default:
+				f.remove(sub)
+			}
+			if _, ok := f.subscribers[sub]; !ok {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WriteCSV записывает документы в CSV. Вложенные объекты разворачиваются в колонки
+// через точку (address.city), массивы записываются в JSON. Колонки идут в порядке:
+// id, поля схемы, остальные поля по алфавиту. Поля схемы попадают в заголовок,
+// даже если документов нет, - такой файл служит шаблоном для импорта
+func WriteCSV(w io.Writer, schema *models.Collection, docs []map[string]any) error {
+	rows := make([]map[string]string, len(docs))
+	columns := map[string]bool{}
+	for i, doc := range docs {
+		rows[i] = map[string]string{}
+		flattenCSV("", plainDocument(doc), rows[i])
+		for column := range rows[i] {
+			columns[column] = true
+		}
+	}
+
+	header := csvHeader(schema, columns)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		for i, column := range header {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvHeader упорядочивает колонки CSV: id, поля схемы (с вложенными колонками), остальные
+func csvHeader(schema *models.Collection, columns map[string]bool) []string {
+	rank := map[string]int{"id": 0}
+	if schema != nil {
+		for i, field := range schema.Fields {
+			if _, ok := rank[field.Name]; !ok {
+				rank[field.Name] = i + 1
+			}
+			// Поле схемы без значений в документах все равно попадает в заголовок
+			if !hasNestedColumn(columns, field.Name) {
+				columns[field.Name] = true
+			}
+		}
+	}
+	if len(columns) > 0 {
+		columns["id"] = true
+	}
+
+	columnRank := func(column string) int {
+		top, _, _ := strings.Cut(column, ".")
+		if r, ok := rank[top]; ok {
+			return r
+		}
+		return len(rank) + 1
+	}
+
+	header := make([]string, 0, len(columns))
+	for column := range columns {
+		header = append(header, column)
+	}
+	sort.Slice(header, func(i, j int) bool {
+		ri, rj := columnRank(header[i]), columnRank(header[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return header[i] < header[j]
+	})
+	return header
+}
+
+func hasNestedColumn(columns map[string]bool, name string) bool {
+	if columns[name] {
+		return true
+	}
+	for column := range columns {
+		if strings.HasPrefix(column, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenCSV разворачивает вложенные объекты в колонки через точку
+func flattenCSV(prefix string, value any, row map[string]string) {
+	if doc, ok := value.(map[string]any); ok && (len(doc) > 0 || prefix == "") {
+		for key, nested := range doc {
+			column := key
+			if prefix != "" {
+				column = prefix + "." + key
+			}
+			flattenCSV(column, nested, row)
+		}
+		return
+	}
+	row[prefix] = formatCSVValue(value)
+}
+
+// formatCSVValue записывает значение ячейки: даты в RFC 3339, числа без экспоненты,
+// массивы и пустые объекты в JSON
+func formatCSVValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int, int32, int64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case bson.DateTime:
+		return v.Time().UTC().Format(time.RFC3339)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// ImportCSV создает документы из CSV. Первая строка - имена полей (вложенные через точку),
+// пустые ячейки пропускаются. Значения приводятся к типам полей схемы коллекции,
+// для полей без схемы тип определяется по виду значения. Строки создаются как операции
+// create пакетного запроса: index результата - номер строки данных, начиная с 0
+func (s *DocumentService) ImportCSV(projectID int64, collectionName string, schema *models.Collection, r io.Reader) (*model.BulkResponse, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	// Excel добавляет BOM в начало файла
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+		if header[i] == "" {
+			return nil, fmt.Errorf("invalid CSV: column %d has no name", i+1)
+		}
+	}
+
+	fields := map[string]models.FieldTemplate{}
+	if schema != nil {
+		for _, field := range schema.Fields {
+			fields[field.Name] = field
+		}
+	}
+
+	var ops []model.BulkOperation
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+
+		data := map[string]any{}
+		for i, cell := range record {
+			if i >= len(header) || cell == "" {
+				continue
+			}
+			field, ok := fields[header[i]]
+			if !ok && header[i] == "id" {
+				field, ok = models.FieldTemplate{Name: "id", Type: "reference"}, true
+			}
+			setCSVPath(data, header[i], parseCSVValue(field, ok, cell))
+		}
+		ops = append(ops, model.BulkOperation{Op: model.BulkCreate, Data: data})
+	}
+	if len(ops) == 0 {
+		return nil, errors.New("CSV has no data rows")
+	}
+
+	return s.BulkWrite(projectID, collectionName, schema, ops, model.ChangeSourceImport)
+}
+
+// parseCSVValue приводит ячейку к типу поля схемы. Значение, которое не удалось
+// привести, остается строкой, и валидация схемы сообщает о нарушении
+func parseCSVValue(field models.FieldTemplate, inSchema bool, cell string) any {
+	if !inSchema {
+		return inferCSVValue(cell)
+	}
+
+	switch field.Type {
+	case "number":
+		if v, err := strconv.ParseFloat(strings.TrimSpace(cell), 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(strings.TrimSpace(cell)); err == nil {
+			return v
+		}
+	case "reference":
+		if field.Cardinality != models.CardinalityMany {
+			return inferValue(strings.TrimSpace(cell))
+		}
+		var ids []any
+		if strings.HasPrefix(cell, "[") && json.Unmarshal([]byte(cell), &ids) == nil {
+			return ids
+		}
+		ids = []any{}
+		for _, item := range splitList([]string{cell}) {
+			ids = append(ids, inferValue(item))
+		}
+		return ids
+	}
+	// Строки и даты передаются как есть: даты разбирает валидация схемы
+	return cell
+}
+
+// inferCSVValue определяет тип ячейки поля без схемы: JSON-массив или объект,
+// true/false, число или строка
+func inferCSVValue(cell string) any {
+	trimmed := strings.TrimSpace(cell)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		var v any
+		if json.Unmarshal([]byte(trimmed), &v) == nil {
+			return v
+		}
+	}
+	switch trimmed {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	// Ведущие нули (почтовые индексы, коды) означают строку, а не число
+	if len(trimmed) > 1 && trimmed[0] == '0' && trimmed[1] >= '0' && trimmed[1] <= '9' {
+		return cell
+	}
+	return inferValue(trimmed)
+}
+
+// setCSVPath записывает значение по имени колонки, создавая вложенные объекты для a.b.c
+func setCSVPath(data map[string]any, column string, value any) {
+	parts := strings.Split(column, ".")
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// cursorToken содержимое непрозрачного курсора: сигнатура сортировки
+// и значения ключей сортировки последнего документа страницы
+type cursorToken struct {
+	Sort   string `bson:"s"`
+	Values []any  `bson:"v"`
+}
+
+// cursorSortKeys дополняет сортировку ключом data.id, чтобы порядок документов
+// с одинаковыми значениями сортировки был однозначным
+func cursorSortKeys(keys []model.SortKey) []model.SortKey {
+	if len(keys) == 0 {
+		keys = []model.SortKey{{Field: model.MetaCreatedAt, Desc: true}}
+	}
+
+	for _, key := range keys {
+		if key.Field == "id" {
+			return keys
+		}
+	}
+	return append(keys, model.SortKey{Field: "id"})
+}
+
+// sortSignature описывает сортировку, чтобы курсор нельзя было применить к другой сортировке
+func sortSignature(keys []model.SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		if key.Desc {
+			parts[i] = "-" + key.Field
+		} else {
+			parts[i] = "+" + key.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// encodeCursor формирует курсор на документ, следующий за doc
+func encodeCursor(keys []model.SortKey, doc *model.MockDocument) (string, error) {
+	token := cursorToken{Sort: sortSignature(keys)}
+	for _, key := range keys {
+		token.Values = append(token.Values, sortValue(doc, key.Field))
+	}
+
+	raw, err := bson.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor возвращает значения ключей сортировки из курсора
+func decodeCursor(keys []model.SortKey, cursor string) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	var token cursorToken
+	if err := bson.Unmarshal(raw, &token); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	if token.Sort != sortSignature(keys) || len(token.Values) != len(keys) {
+		return nil, fmt.Errorf("%w: cursor does not match current sort", ErrInvalidQuery)
+	}
+	return token.Values, nil
+}
+
+// sortValue возвращает значение поля сортировки документа (поддерживает вложенные поля)
+func sortValue(doc *model.MockDocument, field string) any {
+	switch field {
+	case model.MetaCreatedAt:
+		return doc.CreatedAt
+	case model.MetaUpdatedAt:
+		return doc.UpdatedAt
+	}
+
+	var current any = doc.Data
+	for _, part := range strings.Split(field, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			current = v[part]
+		case bson.D:
+			current = nil
+			for _, e := range v {
+				if e.Key == part {
+					current = e.Value
+					break
+				}
+			}
+		default:
+			return nil
+		}
+	}
+	return current
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestCursorSortKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []model.SortKey
+		want []model.SortKey
+	}{
+		{
+			name: "default sort",
+			keys: nil,
+			want: []model.SortKey{{Field: model.MetaCreatedAt, Desc: true}, {Field: "id"}},
+		},
+		{
+			name: "id appended as tie breaker",
+			keys: []model.SortKey{{Field: "price", Desc: true}},
+			want: []model.SortKey{{Field: "price", Desc: true}, {Field: "id"}},
+		},
+		{
+			name: "id already present",
+			keys: []model.SortKey{{Field: "id", Desc: true}, {Field: "name"}},
+			want: []model.SortKey{{Field: "id", Desc: true}, {Field: "name"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cursorSortKeys(tt.keys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cursorSortKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		keys []model.SortKey
+		doc  *model.MockDocument
+		want []any
+	}{
+		{
+			name: "string and id",
+			keys: []model.SortKey{{Field: "name"}, {Field: "id"}},
+			doc:  &model.MockDocument{Data: map[string]any{"id": int64(7), "name": "Alice"}},
+			want: []any{"Alice", int64(7)},
+		},
+		{
+			name: "numbers keep their BSON type",
+			keys: []model.SortKey{{Field: "price", Desc: true}, {Field: "rating"}, {Field: "id"}},
+			doc:  &model.MockDocument{Data: map[string]any{"id": int32(1), "price": 9.5, "rating": int64(4)}},
+			want: []any{9.5, int64(4), int32(1)},
+		},
+		{
+			name: "bool",
+			keys: []model.SortKey{{Field: "active"}, {Field: "id"}},
+			doc:  &model.MockDocument{Data: map[string]any{"id": "a", "active": true}},
+			want: []any{true, "a"},
+		},
+		{
+			name: "missing field is null",
+			keys: []model.SortKey{{Field: "email"}, {Field: "id"}},
+			doc:  &model.MockDocument{Data: map[string]any{"id": "a"}},
+			want: []any{nil, "a"},
+		},
+		{
+			name: "nested field",
+			keys: []model.SortKey{{Field: "address.city"}, {Field: "id"}},
+			doc: &model.MockDocument{Data: map[string]any{
+				"id":      "a",
+				"address": bson.D{{Key: "city", Value: "Oslo"}},
+			}},
+			want: []any{"Oslo", "a"},
+		},
+		{
+			name: "metadata date",
+			keys: []model.SortKey{{Field: model.MetaCreatedAt, Desc: true}, {Field: "id"}},
+			doc:  &model.MockDocument{CreatedAt: created, Data: map[string]any{"id": "a"}},
+			want: []any{bson.NewDateTimeFromTime(created), "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := encodeCursor(tt.keys, tt.doc)
+			if err != nil {
+				t.Fatalf("encodeCursor() error = %v", err)
+			}
+			got, err := decodeCursor(tt.keys, cursor)
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCursor() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	keys := []model.SortKey{{Field: "name"}, {Field: "id"}}
+	doc := &model.MockDocument{Data: map[string]any{"id": "a", "name": "Alice"}}
+	cursor, err := encodeCursor(keys, doc)
+	if err != nil {
+		t.Fatalf("encodeCursor() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		keys   []model.SortKey
+		cursor string
+	}{
+		{name: "not base64", keys: keys, cursor: "not a cursor!"},
+		{name: "not bson", keys: keys, cursor: "AAAA"},
+		{name: "other sort field", keys: []model.SortKey{{Field: "email"}, {Field: "id"}}, cursor: cursor},
+		{name: "other sort direction", keys: []model.SortKey{{Field: "name", Desc: true}, {Field: "id"}}, cursor: cursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.keys, tt.cursor); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("decodeCursor() error = %v, want ErrInvalidQuery", err)
+			}
+		})
+	}
+}
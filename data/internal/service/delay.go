@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mockingcode/models"
+)
+
+// percentilePoint точка распределения задержки: перцентиль и задержка, мс
+type percentilePoint struct {
+	percentile float64
+	ms         float64
+}
+
+// ResponseDelay возвращает имитируемую задержку ответа. Параметр ?_delay
+// (мс или диапазон min-max) имеет приоритет над настройкой коллекции
+func ResponseDelay(config *models.DelayConfig, override string) (time.Duration, error) {
+	if override != "" {
+		delay, err := parseDelayOverride(override)
+		if err != nil {
+			return 0, err
+		}
+		config = delay
+	}
+	if config == nil {
+		return 0, nil
+	}
+	return time.Duration(sampleDelay(config)) * time.Millisecond, nil
+}
+
+// parseDelayOverride разбирает ?_delay=300 или ?_delay=100-800
+func parseDelayOverride(raw string) (*models.DelayConfig, error) {
+	minRaw, maxRaw, isRange := strings.Cut(raw, "-")
+	minMs, err := strconv.Atoi(minRaw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: _delay must be milliseconds or a min-max range", ErrInvalidQuery)
+	}
+
+	delay := &models.DelayConfig{Fixed: minMs}
+	if isRange {
+		maxMs, err := strconv.Atoi(maxRaw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: _delay must be milliseconds or a min-max range", ErrInvalidQuery)
+		}
+		delay = &models.DelayConfig{Min: minMs, Max: maxMs}
+	}
+
+	if err := delay.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	return delay, nil
+}
+
+// sampleDelay выбирает задержку в мс. Для распределения по перцентилям
+// задержка интерполируется линейно между соседними точками; min задает
+// нижнюю границу (p0), max - хвост выше последнего перцентиля
+func sampleDelay(config *models.DelayConfig) int {
+	switch {
+	case config.Fixed > 0:
+		return config.Fixed
+	case len(config.Percentiles) > 0:
+		return samplePercentiles(config)
+	case config.Max > 0:
+		return config.Min + rand.IntN(config.Max-config.Min+1)
+	default:
+		return 0
+	}
+}
+
+func samplePercentiles(config *models.DelayConfig) int {
+	points := []percentilePoint{{percentile: 0, ms: float64(config.Min)}}
+	for key, ms := range config.Percentiles {
+		percentile, err := models.ParsePercentile(key)
+		if err != nil {
+			continue
+		}
+		points = append(points, percentilePoint{percentile: percentile, ms: float64(ms)})
+	}
+	slices.SortFunc(points, func(a, b percentilePoint) int {
+		if a.percentile < b.percentile {
+			return -1
+		}
+		if a.percentile > b.percentile {
+			return 1
+		}
+		return 0
+	})
+
+	last := points[len(points)-1]
+	if last.percentile < 100 && config.Max > int(last.ms) {
+		points = append(points, percentilePoint{percentile: 100, ms: float64(config.Max)})
+	}
+
+	p := rand.Float64() * 100
+	for i := 1; i < len(points); i++ {
+		lo, hi := points[i-1], points[i]
+		if p <= hi.percentile {
+			ratio := (p - lo.percentile) / (hi.percentile - lo.percentile)
+			return int(lo.ms + ratio*(hi.ms-lo.ms))
+		}
+	}
+	// Выше последнего перцентиля без max - задержка последней точки
+	return int(points[len(points)-1].ms)
+}
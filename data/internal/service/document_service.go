@@ -4,6 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/go-mockingcode/data/internal/model"
 	"github.com/go-mockingcode/data/internal/repository"
@@ -12,21 +17,71 @@ import (
 
 type DocumentService struct {
 	docRepo              *repository.DocumentRepository
-	generator            *DataGenerator
 	maxDocsPerCollection int
+	graphQLSchemas       *graphQLSchemaCache
 }
 
 func NewDocumentService(docRepo *repository.DocumentRepository, maxDocsPerCollection int) *DocumentService {
 	return &DocumentService{
 		docRepo:              docRepo,
-		generator:            NewDataGenerator(0), // random seed
 		maxDocsPerCollection: maxDocsPerCollection,
+		graphQLSchemas:       newGraphQLSchemaCache(),
 	}
 }
 
-// GetDocuments возвращает документы коллекции
-func (s *DocumentService) GetDocuments(projectID int64, collectionName string, opts model.QueryOptions) (*model.DocumentsResponse, error) {
-	return s.docRepo.GetDocuments(projectID, collectionName, opts)
+// GetDocuments возвращает документы коллекции.
+// schema может быть nil для коллекций без описанной схемы
+func (s *DocumentService) GetDocuments(projectID int64, collectionName string, schema *models.Collection, opts model.QueryOptions) (*model.DocumentsResponse, error) {
+	if err := prepareQuery(schema, &opts); err != nil {
+		return nil, err
+	}
+
+	// Курсорная пагинация: продолжаем после документа, на который указывает курсор
+	if opts.Cursor != nil {
+		opts.SortKeys = cursorSortKeys(opts.SortKeys)
+		if *opts.Cursor != "" {
+			after, err := decodeCursor(opts.SortKeys, *opts.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			opts.After = after
+		}
+	}
+
+	response, err := s.docRepo.GetDocuments(projectID, collectionName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Cursor != nil && response.HasMore && len(response.Documents) > 0 {
+		nextCursor, err := encodeCursor(opts.SortKeys, response.Documents[len(response.Documents)-1])
+		if err != nil {
+			return nil, err
+		}
+		response.NextCursor = nextCursor
+	}
+
+	return response, nil
+}
+
+// prepareQuery приводит фильтры, сортировку и поиск запроса к схеме коллекции
+func prepareQuery(schema *models.Collection, opts *model.QueryOptions) error {
+	filters, err := prepareFilters(schema, opts.Filters)
+	if err != nil {
+		return err
+	}
+	opts.Filters = filters
+
+	sortKeys, err := prepareSort(schema, opts.Sort, opts.Order)
+	if err != nil {
+		return err
+	}
+	opts.SortKeys = sortKeys
+
+	if opts.Search != "" {
+		opts.SearchFields = searchFields(schema)
+	}
+	return nil
 }
 
 // GetDocument возвращает документ по ID
@@ -34,8 +89,16 @@ func (s *DocumentService) GetDocument(projectID int64, collectionName, documentI
 	return s.docRepo.GetDocumentByID(projectID, collectionName, documentID)
 }
 
-// CreateDocument создает новый документ
-func (s *DocumentService) CreateDocument(projectID int64, collectionName string, data map[string]interface{}) (*model.MockDocument, error) {
+// CreateDocument создает новый документ.
+// schema может быть nil для коллекций без описанной схемы, source - источник изменения для истории
+func (s *DocumentService) CreateDocument(projectID int64, collectionName string, schema *models.Collection, data map[string]interface{}, source string) (*model.MockDocument, error) {
+	if err := validateDocument(schema, data); err != nil {
+		return nil, err
+	}
+	if err := s.checkUnique(projectID, collectionName, schema, data, ""); err != nil {
+		return nil, err
+	}
+
 	// Проверяем лимит документов
 	count, err := s.docRepo.CountDocuments(projectID, collectionName)
 	if err != nil {
@@ -46,22 +109,255 @@ func (s *DocumentService) CreateDocument(projectID int64, collectionName string,
 		return nil, fmt.Errorf("maximum documents limit reached: %d", s.maxDocsPerCollection)
 	}
 
-	return s.docRepo.CreateDocument(projectID, collectionName, data)
+	document, err := s.docRepo.CreateDocument(projectID, collectionName, data, uniqueFields(schema), source)
+	return document, conflictError(err)
+}
+
+// UpdateDocument обновляет документ. Непустой ifMatch должен совпадать с ETag документа
+func (s *DocumentService) UpdateDocument(projectID int64, collectionName, documentID string, schema *models.Collection, data map[string]interface{}, ifMatch, source string) (*model.MockDocument, error) {
+	if err := validateDocument(schema, data); err != nil {
+		return nil, err
+	}
+	if err := s.checkUnique(projectID, collectionName, schema, data, documentID); err != nil {
+		return nil, err
+	}
+
+	version, err := s.expectedVersion(projectID, collectionName, documentID, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := s.docRepo.UpdateDocument(projectID, collectionName, documentID, data, uniqueFields(schema), version, source)
+	return document, preconditionError(conflictError(err))
+}
+
+// maxPatchAttempts количество попыток применить патч при параллельных изменениях документа
+const maxPatchAttempts = 5
+
+// PatchDocument частично обновляет документ. Патч применяется к текущему состоянию
+// документа и сохраняется, только если документ не изменился за это время.
+// Результат патча проверяется по схеме коллекции целиком.
+// Непустой ifMatch должен совпадать с ETag документа
+func (s *DocumentService) PatchDocument(projectID int64, collectionName, documentID string, schema *models.Collection, patch DocumentPatch, ifMatch, source string) (*model.MockDocument, error) {
+	for attempt := 0; attempt < maxPatchAttempts; attempt++ {
+		doc, err := s.docRepo.GetDocumentByID(projectID, collectionName, documentID)
+		if err != nil {
+			return nil, err
+		}
+		if ifMatch != "" && (doc == nil || !MatchesETag(ifMatch, doc.ETag(), false)) {
+			return nil, fmt.Errorf("%w: document version does not match If-Match", ErrPreconditionFailed)
+		}
+		if doc == nil {
+			return nil, nil
+		}
+
+		data, err := applyPatch(doc.Data, patch)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := validateDocument(schema, data); err != nil {
+			return nil, err
+		}
+		if err := s.checkUnique(projectID, collectionName, schema, data, documentID); err != nil {
+			return nil, err
+		}
+
+		updated, err := s.docRepo.ReplaceDocumentData(doc, data, uniqueFields(schema), source)
+		if err != nil {
+			return nil, conflictError(err)
+		}
+		if updated != nil {
+			return updated, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: document is being modified concurrently", ErrConflict)
+}
+
+// DeleteDocument удаляет документ. Непустой ifMatch должен совпадать с ETag документа
+func (s *DocumentService) DeleteDocument(projectID int64, collectionName, documentID, ifMatch, source string) error {
+	version, err := s.expectedVersion(projectID, collectionName, documentID, ifMatch)
+	if err != nil {
+		return err
+	}
+	return preconditionError(s.docRepo.DeleteDocument(projectID, collectionName, documentID, version, source))
+}
+
+// expectedVersion проверяет If-Match по текущему состоянию документа и возвращает
+// версию, в которой документ должен быть записан (nil - If-Match не передан)
+func (s *DocumentService) expectedVersion(projectID int64, collectionName, documentID, ifMatch string) (*int64, error) {
+	if ifMatch == "" {
+		return nil, nil
+	}
+
+	doc, err := s.docRepo.GetDocumentByID(projectID, collectionName, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil || !MatchesETag(ifMatch, doc.ETag(), false) {
+		return nil, fmt.Errorf("%w: document version does not match If-Match", ErrPreconditionFailed)
+	}
+	return &doc.Version, nil
 }
 
-// UpdateDocument обновляет документ
-func (s *DocumentService) UpdateDocument(projectID int64, collectionName, documentID string, data map[string]interface{}) (*model.MockDocument, error) {
-	return s.docRepo.UpdateDocument(projectID, collectionName, documentID, data)
+// MatchesETag проверяет значение If-Match или If-None-Match: "*" или список тегов через запятую.
+// weak разрешает слабое сравнение (If-None-Match), при котором префикс W/ не учитывается
+func MatchesETag(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionError приводит ошибку записи устаревшей версии документа к ErrPreconditionFailed
+func preconditionError(err error) error {
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		return fmt.Errorf("%w: document was modified concurrently", ErrPreconditionFailed)
+	}
+	return err
+}
+
+// BulkWrite выполняет пакет операций create/update/delete. Некорректные операции
+// отклоняются по отдельности, лимит документов проверяется один раз на весь пакет
+func (s *DocumentService) BulkWrite(projectID int64, collectionName string, schema *models.Collection, ops []model.BulkOperation, source string) (*model.BulkResponse, error) {
+	if len(ops) == 0 {
+		return nil, errors.New("no operations provided")
+	}
+
+	var results []model.BulkItemResult
+	var valid []model.BulkOperation
+	creates := 0
+
+	for i, op := range ops {
+		op.Index = i
+		if err := prepareBulkOperation(&op); err != nil {
+			results = append(results, model.BulkItemResult{
+				Index:  i,
+				Op:     op.Op,
+				Status: http.StatusBadRequest,
+				ID:     op.ID,
+				Error:  err.Error(),
+			})
+			continue
+		}
+		if op.Op != model.BulkDelete {
+			var validationErr *ValidationError
+			if err := validateDocument(schema, op.Data); errors.As(err, &validationErr) {
+				results = append(results, model.BulkItemResult{
+					Index:      i,
+					Op:         op.Op,
+					Status:     http.StatusUnprocessableEntity,
+					ID:         op.ID,
+					Error:      err.Error(),
+					Violations: validationErr.Violations,
+				})
+				continue
+			}
+		}
+		if op.Op == model.BulkCreate {
+			creates++
+		}
+		valid = append(valid, op)
+	}
+
+	// Проверяем лимит
+	if creates > 0 {
+		count, err := s.docRepo.CountDocuments(projectID, collectionName)
+		if err != nil {
+			return nil, err
+		}
+		if count+int64(creates) > int64(s.maxDocsPerCollection) {
+			return nil, fmt.Errorf("cannot create %d documents, would exceed limit of %d", creates, s.maxDocsPerCollection)
+		}
+	}
+
+	valid, rejected, err := s.checkBulkUnique(projectID, collectionName, schema, valid)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, rejected...)
+
+	if len(valid) > 0 {
+		written, err := s.docRepo.BulkWrite(projectID, collectionName, valid, uniqueFields(schema), source)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, written...)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+	response := &model.BulkResponse{Results: results}
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			response.Failed++
+		case result.Op == model.BulkCreate:
+			response.Created++
+		case result.Op == model.BulkUpdate:
+			response.Updated++
+		case result.Op == model.BulkDelete:
+			response.Deleted++
+		}
+	}
+
+	return response, nil
 }
 
-// DeleteDocument удаляет документ
-func (s *DocumentService) DeleteDocument(projectID int64, collectionName, documentID string) error {
-	return s.docRepo.DeleteDocument(projectID, collectionName, documentID)
+// prepareBulkOperation проверяет операцию пакетного запроса и приводит ID к строке
+func prepareBulkOperation(op *model.BulkOperation) error {
+	switch op.Op {
+	case model.BulkCreate:
+		if len(op.Data) == 0 {
+			return errors.New("document data is required")
+		}
+		return nil
+	case model.BulkUpdate:
+		if len(op.Data) == 0 {
+			return errors.New("document data is required")
+		}
+	case model.BulkDelete:
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	switch id := op.ID.(type) {
+	case string:
+		op.DocumentID = id
+		// "007" и 7 указывают на один документ
+		if numID, err := strconv.Atoi(id); err == nil {
+			op.DocumentID = strconv.Itoa(numID)
+		}
+	case float64:
+		if id != math.Trunc(id) {
+			return fmt.Errorf("invalid document ID: %v", id)
+		}
+		op.DocumentID = strconv.FormatInt(int64(id), 10)
+	case nil:
+		return errors.New("document ID is required")
+	default:
+		return fmt.Errorf("invalid document ID: %v", id)
+	}
+
+	if op.DocumentID == "" {
+		return errors.New("document ID is required")
+	}
+	return nil
 }
 
 // FlushCollection очищает коллекцию
-func (s *DocumentService) FlushCollection(projectID int64, collectionName string) (int64, error) {
-	return s.docRepo.DeleteAllDocuments(projectID, collectionName)
+func (s *DocumentService) FlushCollection(projectID int64, collectionName, source string) (int64, error) {
+	return s.docRepo.DeleteAllDocuments(projectID, collectionName, source)
 }
 
 // ResetCounter сбрасывает автоинкрементный счетчик для коллекции
@@ -94,29 +390,61 @@ func (s *DocumentService) GenerateDocuments(projectID int64, collection *models.
 		return nil, fmt.Errorf("cannot generate %d documents, would exceed limit of %d", count, s.maxDocsPerCollection)
 	}
 
-	// Устанавливаем seed если указан
+	// Генератор создается на каждый запрос: Faker не потокобезопасен,
+	// а seed одного запроса не должен влиять на другие
+	var seed uint64 // 0 - случайный seed
 	if req.Seed != nil {
-		s.generator = NewDataGenerator(*req.Seed)
+		seed = *req.Seed
+	}
+	generator := NewDataGenerator(seed)
+
+	// Значения уникальных полей, уже занятые в коллекции,
+	// и id документов, на которые могут ссылаться поля reference
+	existing := ExistingData{Taken: make(map[string][]any), IDs: make(map[string][]any)}
+	for _, field := range collection.Fields {
+		if field.Unique {
+			values, err := s.docRepo.DistinctValues(projectID, collection.Name, field.Name)
+			if err != nil {
+				return nil, err
+			}
+			existing.Taken[field.Name] = values
+		}
+		if field.Type == "reference" && field.Reference != "" {
+			ids, err := s.docRepo.DistinctValues(projectID, field.Reference, "id")
+			if err != nil {
+				return nil, err
+			}
+			existing.IDs[field.Name] = ids
+		}
 	}
 
 	// Генерируем данные
-	generatedData := s.generator.GenerateDocuments(collection.Fields, count)
+	generatedData, err := generator.GenerateDocuments(collection.Fields, count, existing)
+	if err != nil {
+		return nil, err
+	}
 
 	// Сохраняем в БД
-	var documents []*model.MockDocument
-	for i, data := range generatedData {
-		doc, err := s.docRepo.CreateDocument(projectID, collection.Name, data)
-		if err != nil {
-			return nil, err
-		}
-		documents = append(documents, doc)
-		slog.Info("Generated document", 
+	documents, err := s.docRepo.CreateDocuments(projectID, collection.Name, generatedData, uniqueFields(collection), model.ChangeSourceGenerator)
+	for i, doc := range documents {
+		slog.Info("Generated document",
 			slog.Int64("project_id", projectID),
 			slog.String("collection", collection.Name),
 			slog.Int("index", i),
 			slog.String("doc_id", doc.ID.Hex()),
 		)
 	}
+	if err != nil {
+		return nil, conflictError(err)
+	}
 
 	return documents, nil
 }
+
+// conflictError превращает нарушение уникальности в ErrConflict
+func conflictError(err error) error {
+	if errors.Is(err, repository.ErrDuplicateValue) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
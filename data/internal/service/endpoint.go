@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+)
+
+// RequestData данные запроса для шаблона ответа маршрута и условий ответов (ResponseRule)
+type RequestData struct {
+	Method  string
+	Path    string
+	Params  map[string]string // Параметры пути: /users/{id} -> .Params.id
+	Query   map[string]string // Первое значение каждого query-параметра
+	Headers map[string]string // Заголовки в каноническом виде: index .Headers "X-User-Id"
+	Body    any               // Разобранное JSON-тело или исходная строка
+}
+
+// MatchEndpoint находит маршрут по методу и пути. При нескольких совпадениях
+// выбирается маршрут с большим числом постоянных сегментов (/users/me раньше /users/{id})
+func MatchEndpoint(endpoints []models.Endpoint, method, path string) (*models.Endpoint, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var best *models.Endpoint
+	var bestParams map[string]string
+	bestLiterals := -1
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if endpoint.Method != method {
+			continue
+		}
+		params, literals, ok := matchPath(endpoint.Path, segments)
+		if ok && literals > bestLiterals {
+			best, bestParams, bestLiterals = endpoint, params, literals
+		}
+	}
+	return best, bestParams
+}
+
+// matchPath сопоставляет сегменты пути с шаблоном и возвращает параметры и число постоянных сегментов
+func matchPath(pattern string, segments []string) (map[string]string, int, bool) {
+	parts := strings.Split(strings.Trim(pattern, "/"), "/")
+	if len(parts) != len(segments) {
+		return nil, 0, false
+	}
+
+	params := make(map[string]string)
+	literals := 0
+	for i, part := range parts {
+		if name, ok := strings.CutPrefix(part, "{"); ok && strings.HasSuffix(name, "}") {
+			params[strings.TrimSuffix(name, "}")] = segments[i]
+			continue
+		}
+		if part != segments[i] {
+			return nil, 0, false
+		}
+		literals++
+	}
+	return params, literals, true
+}
+
+// RenderEndpoint формирует тело ответа маршрута по шаблону text/template.
+// Функции lookup и find читают документы коллекций проекта
+func (s *DocumentService) RenderEndpoint(projectID int64, endpoint *models.Endpoint, req RequestData) ([]byte, error) {
+	tmpl, err := template.New("body").
+		Option("missingkey=zero").
+		Funcs(s.endpointFuncs(projectID)).
+		Parse(endpoint.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, req); err != nil {
+		return nil, fmt.Errorf("failed to render endpoint template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// endpointFuncs реализует функции шаблона, перечисленные в models.EndpointFuncs
+func (s *DocumentService) endpointFuncs(projectID int64) template.FuncMap {
+	return template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"lookup": func(collectionName string, id any) (model.CleanDocument, error) {
+			if err := models.ValidateCollectionName(collectionName); err != nil {
+				return nil, err
+			}
+			doc, err := s.docRepo.GetDocumentByID(projectID, collectionName, fmt.Sprint(id))
+			if err != nil || doc == nil {
+				// Некорректный ID равнозначен отсутствующему документу
+				return nil, nil
+			}
+			return doc.ToClean(), nil
+		},
+		"find": func(collectionName, field string, value any) ([]model.CleanDocument, error) {
+			if err := models.ValidateCollectionName(collectionName); err != nil {
+				return nil, err
+			}
+			values := []any{value}
+			if raw, ok := value.(string); ok {
+				values = inferValues(raw)
+			}
+			docs, err := s.docRepo.FindByField(projectID, collectionName, field, values)
+			if err != nil {
+				return nil, err
+			}
+			result := make([]model.CleanDocument, len(docs))
+			for i, doc := range docs {
+				result[i] = doc.ToClean()
+			}
+			return result, nil
+		},
+		"now": func() string {
+			return time.Now().UTC().Format(time.RFC3339)
+		},
+		"uuid": func() string {
+			return gofakeit.UUID()
+		},
+		"default": func(fallback, value any) any {
+			if value == nil || value == "" {
+				return fallback
+			}
+			return value
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-mockingcode/data/internal/model"
+)
+
+var (
+	// ErrInvalidQuery ошибка в параметрах запроса (фильтры, сортировка, пагинация)
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrInvalidPatch патч не может быть применен к документу (несуществующий путь и т.п.)
+	ErrInvalidPatch = errors.New("invalid patch")
+
+	// ErrConflict операция конфликтует с текущим состоянием документа
+	ErrConflict = errors.New("conflict")
+
+	// ErrPreconditionFailed If-Match не совпадает с текущей версией документа
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrIdempotencyKeyReused Idempotency-Key повторно использован с другим запросом
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused")
+
+	// ErrNotSupported операция недоступна в текущей конфигурации сервера
+	ErrNotSupported = errors.New("not supported")
+)
+
+// ValidationError документ не соответствует схеме коллекции
+type ValidationError struct {
+	Violations []model.FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("document does not match collection schema: %d violation(s)", len(e.Violations))
+}
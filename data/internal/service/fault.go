@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-mockingcode/models"
+)
+
+// PickFault выбирает сбой для запроса. Заголовок X-Mock-Fail (код ответа, drop или
+// truncate) принудительно вызывает сбой: берется подходящее правило коллекции, а без
+// него - сбой с ответом по умолчанию. Иначе правила проверяются по порядку, и
+// срабатывает первое, подходящее по методу, с вероятностью probability.
+// Возвращает nil, если запрос обрабатывается как обычно
+func PickFault(rules []models.FaultRule, method, forced string) (*models.FaultRule, error) {
+	if forced != "" {
+		return forcedFault(rules, method, forced)
+	}
+
+	for _, rule := range rules {
+		if !faultMatchesMethod(rule, method) {
+			continue
+		}
+		if rule.Probability > 0 && rand.Float64() < rule.Probability {
+			return faultWithDefaults(rule), nil
+		}
+	}
+	return nil, nil
+}
+
+func forcedFault(rules []models.FaultRule, method, forced string) (*models.FaultRule, error) {
+	want := models.FaultRule{Type: strings.ToLower(forced)}
+	if status, err := strconv.Atoi(forced); err == nil {
+		want = models.FaultRule{Type: models.FaultError, Status: status}
+	}
+	if err := want.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: X-Mock-Fail must be an error status (400-599), drop or truncate", ErrInvalidQuery)
+	}
+
+	for _, rule := range rules {
+		if rule.Kind() != want.Kind() || !faultMatchesMethod(rule, method) {
+			continue
+		}
+		if want.Status == 0 || rule.Status == want.Status {
+			return faultWithDefaults(rule), nil
+		}
+	}
+	return faultWithDefaults(want), nil
+}
+
+func faultMatchesMethod(rule models.FaultRule, method string) bool {
+	if len(rule.Methods) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(rule.Methods, func(m string) bool { return strings.EqualFold(m, method) })
+}
+
+// faultWithDefaults дополняет правило кодом и телом ответа по умолчанию
+func faultWithDefaults(rule models.FaultRule) *models.FaultRule {
+	rule.Type = rule.Kind()
+	if rule.Type == models.FaultError {
+		if rule.Status == 0 {
+			rule.Status = http.StatusInternalServerError
+		}
+		if rule.Body == nil {
+			rule.Body = map[string]string{"error": http.StatusText(rule.Status)}
+		}
+	}
+	return &rule
+}
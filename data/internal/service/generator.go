@@ -1,10 +1,13 @@
 package service
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type DataGenerator struct {
@@ -18,15 +21,57 @@ func NewDataGenerator(seed uint64) *DataGenerator {
 	}
 }
 
-// GenerateDocuments генерирует массив документов по шаблону
-func (g *DataGenerator) GenerateDocuments(fields []models.FieldTemplate, count int) []map[string]interface{} {
+// maxUniqueAttempts количество попыток сгенерировать незанятое значение уникального поля
+const maxUniqueAttempts = 100
+
+// ExistingData уже сохраненные данные проекта, которые учитываются при генерации
+type ExistingData struct {
+	Taken map[string][]any // Занятые значения уникальных полей (имя поля -> значения)
+	IDs   map[string][]any // id документов целевых коллекций полей reference (имя поля -> id)
+}
+
+// GenerateDocuments генерирует массив документов по шаблону.
+// Значения полей с Unique не повторяются внутри массива и не совпадают с занятыми,
+// поля reference заполняются id существующих документов целевой коллекции
+func (g *DataGenerator) GenerateDocuments(fields []models.FieldTemplate, count int, existing ExistingData) ([]map[string]interface{}, error) {
+	seen := make(map[string]map[string]bool)
+	for _, field := range fields {
+		if !field.Unique || field.Name == "id" {
+			continue
+		}
+		seen[field.Name] = make(map[string]bool)
+		for _, value := range existing.Taken[field.Name] {
+			seen[field.Name][uniqueKey(value)] = true
+		}
+	}
+
 	documents := make([]map[string]interface{}, count)
 
 	for i := 0; i < count; i++ {
-		documents[i] = g.GenerateDocument(fields)
+		doc := g.GenerateDocument(fields)
+		for _, field := range fields {
+			generate := func() any { return g.generateField(field) }
+			if field.Type == "reference" {
+				ids := existing.IDs[field.Name]
+				generate = func() any { return g.generateReference(field, ids) }
+				doc[field.Name] = generate()
+			}
+
+			values, unique := seen[field.Name]
+			if !unique {
+				continue
+			}
+			value, err := g.uniqueValue(field, doc[field.Name], values, generate)
+			if err != nil {
+				return nil, err
+			}
+			doc[field.Name] = value
+			values[uniqueKey(value)] = true
+		}
+		documents[i] = doc
 	}
 
-	return documents
+	return documents, nil
 }
 
 // GenerateDocument генерирует один документ по шаблону полей
@@ -54,6 +99,9 @@ func (g *DataGenerator) generateField(field models.FieldTemplate) interface{} {
 		return g.generateBoolean(field)
 	case "date":
 		return g.generateDateTime(field)
+	case "reference":
+		// Заполняется в GenerateDocuments: генератору нужны id целевой коллекции
+		return nil
 	default:
 		return g.generateString(field)
 	}
@@ -108,3 +156,74 @@ func (g *DataGenerator) generateDateTime(_ models.FieldTemplate) time.Time {
 func (g *DataGenerator) generateBoolean(_ models.FieldTemplate) bool {
 	return g.fake.Bool()
 }
+
+// maxGeneratedReferences максимальное количество ссылок в сгенерированном поле reference "many"
+const maxGeneratedReferences = 3
+
+// generateReference выбирает случайные id из целевой коллекции.
+// Если целевая коллекция пуста, ссылка остается пустой
+func (g *DataGenerator) generateReference(field models.FieldTemplate, ids []any) any {
+	if field.Cardinality != models.CardinalityMany {
+		if len(ids) == 0 {
+			return nil
+		}
+		return ids[g.fake.IntN(len(ids))]
+	}
+
+	refs := []any{}
+	if len(ids) == 0 {
+		return refs
+	}
+	// Частичная перестановка: первые n элементов - случайные различные id
+	pool := append([]any(nil), ids...)
+	n := g.fake.IntRange(1, min(maxGeneratedReferences, len(pool)))
+	for i := 0; i < n; i++ {
+		j := i + g.fake.IntN(len(pool)-i)
+		pool[i], pool[j] = pool[j], pool[i]
+		refs = append(refs, pool[i])
+	}
+	return refs
+}
+
+// uniqueValue перегенерирует значение, пока оно занято. Если попытки исчерпаны,
+// строка без списка допустимых значений делается уникальной числовым суффиксом
+func (g *DataGenerator) uniqueValue(field models.FieldTemplate, value any, seen map[string]bool, generate func() any) (any, error) {
+	for attempt := 0; seen[uniqueKey(value)]; attempt++ {
+		if attempt == maxUniqueAttempts {
+			s, ok := value.(string)
+			if !ok || len(field.Options) > 0 {
+				return nil, fmt.Errorf("cannot generate unique value for field %q", field.Name)
+			}
+			return uniqueSuffix(field.Format, s, seen), nil
+		}
+		value = generate()
+	}
+	return value, nil
+}
+
+// uniqueSuffix добавляет к строке первый свободный числовой суффикс
+// (для email - к локальной части адреса)
+func uniqueSuffix(format, value string, seen map[string]bool) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s%d", value, n)
+		if local, domain, ok := strings.Cut(value, "@"); ok && format == "email" {
+			candidate = fmt.Sprintf("%s%d@%s", local, n, domain)
+		}
+		if !seen[candidate] {
+			return candidate
+		}
+	}
+}
+
+// uniqueKey ключ значения для проверки уникальности. Даты сравниваются
+// с точностью MongoDB (миллисекунды)
+func uniqueKey(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano)
+	case bson.DateTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,879 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// graphQLCursorLimit размер страницы курсорной пагинации, если limit не указан
+const graphQLCursorLimit = 10
+
+// graphQLMaxLimit наибольший размер страницы списка. Вложенные списки умножают число
+// документов в ответе, поэтому список без limit тоже ограничен этим размером
+const graphQLMaxLimit = 100
+
+// Имена типов, которые не могут получить коллекции
+var graphQLReservedTypes = []string{"Query", "Mutation", "DateTime", "String", "Int", "Float", "Boolean", "ID"}
+
+// Операторы фильтров GraphQL по типу поля схемы (как суффиксы query-параметров REST)
+var graphQLFilterOperators = map[string][]string{
+	"string":    {model.FilterEq, model.FilterNe, model.FilterIn, model.FilterLike, model.FilterExists},
+	"number":    {model.FilterEq, model.FilterNe, model.FilterGt, model.FilterGte, model.FilterLt, model.FilterLte, model.FilterIn, model.FilterExists},
+	"date":      {model.FilterEq, model.FilterNe, model.FilterGt, model.FilterGte, model.FilterLt, model.FilterLte, model.FilterExists},
+	"boolean":   {model.FilterEq, model.FilterNe, model.FilterExists},
+	"reference": {model.FilterEq, model.FilterNe, model.FilterIn, model.FilterExists},
+}
+
+// dateTimeScalar даты документов в RFC 3339. Входные значения передаются как есть
+// и приводятся к time.Time валидацией схемы
+var dateTimeScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "DateTime",
+	Description: "Date and time in RFC 3339 format (input also accepts YYYY-MM-DD)",
+	Serialize: func(value any) any {
+		switch v := value.(type) {
+		case time.Time:
+			return v.UTC().Format(time.RFC3339Nano)
+		case bson.DateTime:
+			return v.Time().UTC().Format(time.RFC3339Nano)
+		default:
+			return v
+		}
+	},
+	ParseValue: func(value any) any {
+		if s, ok := value.(string); ok {
+			return s
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) any {
+		if v, ok := valueAST.(*ast.StringValue); ok {
+			return v.Value
+		}
+		return nil
+	},
+})
+
+// ExecuteGraphQL выполняет запрос GraphQL по схеме, построенной из коллекций проекта:
+// для каждой коллекции - список с фильтрами и пагинацией, документ по id, связи reference
+// в обе стороны и мутации create/update/delete. Коллекции без схемы в GraphQL не попадают.
+// Запрос проверяется на длину, глубину и число полей до выполнения.
+// source - источник изменения для истории
+func (s *DocumentService) ExecuteGraphQL(ctx context.Context, projectID int64, collections []models.Collection, req model.GraphQLRequest, source string) *graphql.Result {
+	if len(req.Query) > maxGraphQLQueryLength {
+		err := fmt.Errorf("query is longer than %d bytes", maxGraphQLQueryLength)
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	schema, err := s.graphQLSchemas.get(projectID, collections, func() (graphql.Schema, error) {
+		return newGraphQLBuilder(s, projectID, collections).schema()
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	document, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	// Циклы фрагментов проверяются отдельно и первыми: на них стандартное правило
+	// OverlappingFieldsCanBeMerged уходит в бесконечную рекурсию
+	for _, rules := range [][]graphql.ValidationRuleFn{{graphql.NoFragmentCyclesRule}, graphQLValidationRules} {
+		if validation := graphql.ValidateDocument(&schema, document, rules); !validation.IsValid {
+			return &graphql.Result{Errors: validation.Errors}
+		}
+	}
+
+	// Загрузчик связей и источник изменений свои у каждого запроса, схема - общая
+	ctx = context.WithValue(ctx, graphQLRequestKey{}, &graphQLRequest{
+		source: source,
+		loader: &graphQLLoader{s: s, projectID: projectID, batches: map[string]*graphQLBatch{}},
+	})
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// IsGraphQLMutation сообщает, что запрос выполняет мутацию (GET допускает только чтение).
+// Запрос с синтаксической ошибкой мутацией не считается: ошибку вернет выполнение
+func IsGraphQLMutation(req model.GraphQLRequest) bool {
+	document, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return false
+	}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName == "" || (operation.Name != nil && operation.Name.Value == req.OperationName) {
+			return operation.Operation == ast.OperationTypeMutation
+		}
+	}
+	return false
+}
+
+// graphQLCollection коллекция проекта в схеме GraphQL
+type graphQLCollection struct {
+	schema    *models.Collection
+	typeName  string // User
+	listField string // users
+	itemField string // user
+	object    *graphql.Object
+	fields    map[string]models.FieldTemplate // Поля входных типов мутаций по имени в GraphQL
+	filters   map[string]model.FieldFilter    // Поля фильтра: поле и оператор
+}
+
+// graphQLBuilder строит схему GraphQL проекта. Схема кэшируется и используется
+// разными запросами, поэтому резолверы берут состояние запроса из контекста (graphQLRequest)
+type graphQLBuilder struct {
+	s           *DocumentService
+	projectID   int64
+	collections []*graphQLCollection
+	byName      map[string]*graphQLCollection
+}
+
+// graphQLRequest состояние одного запроса GraphQL: источник изменений для истории
+// и загрузчик связанных документов
+type graphQLRequest struct {
+	source string
+	loader *graphQLLoader
+}
+
+type graphQLRequestKey struct{}
+
+// request возвращает состояние запроса, который выполняет резолвер
+func (b *graphQLBuilder) request(p graphql.ResolveParams) *graphQLRequest {
+	return p.Context.Value(graphQLRequestKey{}).(*graphQLRequest)
+}
+
+func newGraphQLBuilder(s *DocumentService, projectID int64, collections []models.Collection) *graphQLBuilder {
+	b := &graphQLBuilder{
+		s:         s,
+		projectID: projectID,
+		byName:    map[string]*graphQLCollection{},
+	}
+
+	types := map[string]bool{}
+	for _, name := range graphQLReservedTypes {
+		types[name] = true
+	}
+	rootFields := map[string]bool{}
+
+	for i := range collections {
+		schema := &collections[i]
+		if err := models.ValidateCollectionName(schema.Name); err != nil {
+			slog.Debug("collection skipped in GraphQL schema: reserved name",
+				slog.Int64("project_id", projectID),
+				slog.String("collection", schema.Name))
+			continue
+		}
+		c := newGraphQLCollection(schema, types, rootFields)
+		if c == nil {
+			slog.Debug("collection skipped in GraphQL schema: name conflicts with another collection",
+				slog.Int64("project_id", projectID),
+				slog.String("collection", schema.Name))
+			continue
+		}
+		b.collections = append(b.collections, c)
+		b.byName[schema.Name] = c
+	}
+	return b
+}
+
+// newGraphQLCollection выбирает имена типа и полей запроса коллекции: users -> User, users, user.
+// Если имена заняты другой коллекцией, используется полное имя (UsersPage); nil - конфликт остался
+func newGraphQLCollection(schema *models.Collection, types, rootFields map[string]bool) *graphQLCollection {
+	listField := lowerFirst(pascalName(schema.Name))
+	for _, typeName := range []string{pascalName(singular(schema.Name)), pascalName(schema.Name)} {
+		itemField := lowerFirst(typeName)
+		if itemField == listField {
+			itemField += "ById"
+		}
+		names := []string{typeName, typeName + "Page", typeName + "Filter", typeName + "CreateInput", typeName + "UpdateInput"}
+		if typeName == "" || slices.ContainsFunc(names, func(name string) bool { return types[name] }) ||
+			rootFields[listField] || rootFields[itemField] {
+			continue
+		}
+
+		for _, name := range names {
+			types[name] = true
+		}
+		rootFields[listField], rootFields[itemField] = true, true
+		return &graphQLCollection{
+			schema:    schema,
+			typeName:  typeName,
+			listField: listField,
+			itemField: itemField,
+			fields:    map[string]models.FieldTemplate{},
+			filters:   map[string]model.FieldFilter{},
+		}
+	}
+	return nil
+}
+
+func (b *graphQLBuilder) schema() (graphql.Schema, error) {
+	if len(b.collections) == 0 {
+		return graphql.Schema{}, errors.New("project has no collections with a schema")
+	}
+
+	// Типы ссылаются друг на друга через связи, поэтому поля объектов строятся отложенно
+	for _, c := range b.collections {
+		c.object = graphql.NewObject(graphql.ObjectConfig{
+			Name:        c.typeName,
+			Description: c.schema.Description,
+			Fields:      graphql.FieldsThunk(func() graphql.Fields { return b.objectFields(c) }),
+		})
+	}
+
+	query := graphql.Fields{}
+	mutation := graphql.Fields{}
+	for _, c := range b.collections {
+		b.addQueries(query, c)
+		b.addMutations(mutation, c)
+	}
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: query}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: mutation}),
+	})
+}
+
+// objectFields поля типа коллекции: id, поля схемы, связи reference и обратные связи
+// (документы других коллекций, ссылающиеся на документ: User.posts)
+func (b *graphQLBuilder) objectFields(c *graphQLCollection) graphql.Fields {
+	fields := graphql.Fields{
+		"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: resolveKey("id")},
+	}
+
+	for _, field := range c.schema.Fields {
+		name := graphQLName(field.Name)
+		if name == "" || fields[name] != nil {
+			continue
+		}
+
+		target := b.byName[field.Reference]
+		if field.Type != "reference" || target == nil {
+			fields[name] = &graphql.Field{Type: graphQLOutputType(field), Resolve: resolveKey(field.Name)}
+			continue
+		}
+
+		// userId -> userId (ID) и user (User); поле без суффикса Id заменяется связью
+		relation := graphQLName(relationKey(field.Name))
+		if relation != name {
+			fields[name] = &graphql.Field{Type: graphQLOutputType(field), Resolve: resolveKey(field.Name)}
+		}
+		if relation == "" || fields[relation] != nil {
+			continue
+		}
+		if field.Cardinality == models.CardinalityMany {
+			fields[relation] = &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(target.object))),
+				Resolve: b.resolveReferences(target.schema.Name, field.Name),
+			}
+		} else {
+			fields[relation] = &graphql.Field{
+				Type:    target.object,
+				Resolve: b.resolveReference(target.schema.Name, field.Name),
+			}
+		}
+	}
+
+	for _, child := range b.collections {
+		rel := embedRelation(c.schema.Name, child.schema.Name, child.schema)
+		if !referencesCollection(child.schema, rel.field, c.schema.Name) {
+			continue
+		}
+		name := child.listField
+		if fields[name] != nil {
+			continue
+		}
+		fields[name] = &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(child.object))),
+			Description: fmt.Sprintf("%s documents whose %s references this document", child.schema.Name, rel.field),
+			Resolve:     b.resolveChildren(child.schema.Name, rel.field),
+		}
+	}
+
+	return fields
+}
+
+// referencesCollection проверяет, что поле схемы - ссылка на коллекцию parent
+func referencesCollection(schema *models.Collection, fieldName, parent string) bool {
+	for _, field := range schema.Fields {
+		if field.Name == fieldName {
+			return field.Type == "reference" && field.Reference == parent
+		}
+	}
+	return false
+}
+
+// addQueries добавляет список коллекции (users) и документ по id (user)
+func (b *graphQLBuilder) addQueries(query graphql.Fields, c *graphQLCollection) {
+	page := graphql.NewObject(graphql.ObjectConfig{
+		Name: c.typeName + "Page",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(c.object)))},
+			"total":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"limit":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"offset":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"nextCursor": &graphql.Field{Type: graphql.String, Description: "Cursor of the next page (cursor pagination)"},
+		},
+	})
+
+	query[c.listField] = &graphql.Field{
+		Type:        graphql.NewNonNull(page),
+		Description: fmt.Sprintf("Documents of collection %s", c.schema.Name),
+		Args: graphql.FieldConfigArgument{
+			"filter": &graphql.ArgumentConfig{Type: b.filterType(c)},
+			"q":      &graphql.ArgumentConfig{Type: graphql.String, Description: "Full-text search"},
+			"sort":   &graphql.ArgumentConfig{Type: graphql.String, Description: "Sort keys, minus for descending: -price,name"},
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int, Description: fmt.Sprintf("Page size, at most %d", graphQLMaxLimit)},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int},
+			"cursor": &graphql.ArgumentConfig{Type: graphql.String, Description: "Cursor pagination: empty string for the first page, then nextCursor"},
+		},
+		Resolve: b.resolveList(c),
+	}
+
+	query[c.itemField] = &graphql.Field{
+		Type:        c.object,
+		Description: fmt.Sprintf("Document of collection %s by id", c.schema.Name),
+		Args: graphql.FieldConfigArgument{
+			"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+		},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			doc, err := b.s.GetDocument(b.projectID, c.schema.Name, fmt.Sprint(p.Args["id"]))
+			if err != nil || doc == nil {
+				return nil, err
+			}
+			return graphQLDocument(doc), nil
+		},
+	}
+}
+
+// filterType входной тип фильтра коллекции: поле (равенство) и поле_оператор, как в REST
+func (b *graphQLBuilder) filterType(c *graphQLCollection) *graphql.InputObject {
+	fields := graphql.InputObjectConfigFieldMap{}
+	add := func(name, field, operator string, valueType graphql.Input) {
+		if operator != model.FilterEq {
+			name += "_" + operator
+		}
+		if _, ok := fields[name]; ok {
+			return
+		}
+		switch operator {
+		case model.FilterIn:
+			valueType = graphql.NewList(graphql.NewNonNull(valueType))
+		case model.FilterLike:
+			valueType = graphql.String
+		case model.FilterExists:
+			valueType = graphql.Boolean
+		}
+		fields[name] = &graphql.InputObjectFieldConfig{Type: valueType}
+		c.filters[name] = model.FieldFilter{Field: field, Operator: operator}
+	}
+
+	for _, operator := range []string{model.FilterEq, model.FilterNe, model.FilterIn} {
+		add("id", "id", operator, graphql.ID)
+	}
+	for _, field := range c.schema.Fields {
+		name := graphQLName(field.Name)
+		if name == "" || name == "id" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType == "" {
+			fieldType = "string"
+		}
+		for _, operator := range graphQLFilterOperators[fieldType] {
+			add(name, field.Name, operator, graphQLScalar(field.Type))
+		}
+	}
+
+	return graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:   c.typeName + "Filter",
+		Fields: fields,
+	})
+}
+
+// addMutations добавляет createUser, updateUser (частичное обновление, как merge patch) и deleteUser
+func (b *graphQLBuilder) addMutations(mutation graphql.Fields, c *graphQLCollection) {
+	createFields := graphql.InputObjectConfigFieldMap{}
+	updateFields := graphql.InputObjectConfigFieldMap{}
+	for _, field := range c.schema.Fields {
+		name := graphQLName(field.Name)
+		if name == "" || name == "id" || createFields[name] != nil {
+			continue
+		}
+		c.fields[name] = field
+		inputType := graphQLInputType(field)
+		updateFields[name] = &graphql.InputObjectFieldConfig{Type: inputType}
+		if field.Required {
+			inputType = graphql.NewNonNull(inputType)
+		}
+		createFields[name] = &graphql.InputObjectFieldConfig{Type: inputType}
+	}
+
+	create := &graphql.Field{
+		Type:        graphql.NewNonNull(c.object),
+		Description: fmt.Sprintf("Create a document in collection %s", c.schema.Name),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			data := b.inputData(c, p.Args["input"])
+			doc, err := b.s.CreateDocument(b.projectID, c.schema.Name, c.schema, data, b.request(p).source)
+			if err != nil {
+				return nil, graphQLError(err)
+			}
+			return graphQLDocument(doc), nil
+		},
+	}
+	mutation["create"+c.typeName] = create
+
+	// Коллекция только с id: документ создается без данных, обновлять нечего
+	if len(createFields) > 0 {
+		create.Args = graphql.FieldConfigArgument{
+			"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewInputObject(graphql.InputObjectConfig{
+				Name:   c.typeName + "CreateInput",
+				Fields: createFields,
+			}))},
+		}
+		mutation["update"+c.typeName] = &graphql.Field{
+			Type:        graphql.NewNonNull(c.object),
+			Description: fmt.Sprintf("Update fields of a document in collection %s; null removes a field", c.schema.Name),
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewInputObject(graphql.InputObjectConfig{
+					Name:   c.typeName + "UpdateInput",
+					Fields: updateFields,
+				}))},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				patch := MergePatch(b.inputData(c, p.Args["input"]))
+				doc, err := b.s.PatchDocument(b.projectID, c.schema.Name, fmt.Sprint(p.Args["id"]), c.schema, patch, "", b.request(p).source)
+				if err != nil {
+					return nil, graphQLError(err)
+				}
+				if doc == nil {
+					return nil, &graphQLCodedError{err: errors.New("document not found"), code: "NOT_FOUND"}
+				}
+				return graphQLDocument(doc), nil
+			},
+		}
+	}
+
+	mutation["delete"+c.typeName] = &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.ID),
+		Description: fmt.Sprintf("Delete a document from collection %s, returns its id", c.schema.Name),
+		Args: graphql.FieldConfigArgument{
+			"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+		},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			id := fmt.Sprint(p.Args["id"])
+			if err := b.s.DeleteDocument(b.projectID, c.schema.Name, id, "", b.request(p).source); err != nil {
+				return nil, graphQLError(err)
+			}
+			return id, nil
+		},
+	}
+}
+
+// inputData переводит входной объект мутации в данные документа с исходными именами полей.
+// Ссылки с числовым id сохраняются числами, как их назначает сервис
+func (b *graphQLBuilder) inputData(c *graphQLCollection, input any) map[string]any {
+	data := map[string]any{}
+	values, _ := input.(map[string]any)
+	for name, value := range values {
+		field, ok := c.fields[name]
+		if !ok {
+			continue
+		}
+		if field.Type == "reference" {
+			value = referenceInput(value)
+		}
+		data[field.Name] = value
+	}
+	return data
+}
+
+func referenceInput(value any) any {
+	switch v := value.(type) {
+	case string:
+		if id, err := strconv.Atoi(v); err == nil {
+			return id
+		}
+	case []any:
+		ids := make([]any, len(v))
+		for i, item := range v {
+			ids[i] = referenceInput(item)
+		}
+		return ids
+	}
+	return value
+}
+
+// resolveList выполняет запрос списка через GetDocuments: те же фильтры, поиск,
+// сортировка и пагинация, что у GET /{collection}
+func (b *graphQLBuilder) resolveList(c *graphQLCollection) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		opts := model.QueryOptions{}
+		if sort, ok := p.Args["sort"].(string); ok {
+			opts.Sort = sort
+		}
+		if q, ok := p.Args["q"].(string); ok {
+			opts.Search = strings.TrimSpace(q)
+		}
+		limit, hasLimit := p.Args["limit"].(int)
+		if limit < 0 {
+			return nil, graphQLError(fmt.Errorf("%w: limit must not be negative", ErrInvalidQuery))
+		}
+		// limit 0 в MongoDB означает отсутствие ограничения
+		if !hasLimit || limit == 0 || limit > graphQLMaxLimit {
+			limit = graphQLMaxLimit
+		}
+		if offset, ok := p.Args["offset"].(int); ok {
+			opts.Offset = int64Pointer(offset)
+		}
+		if cursor, ok := p.Args["cursor"].(string); ok {
+			opts.Cursor = &cursor
+			opts.Offset = nil
+			if !hasLimit {
+				limit = graphQLCursorLimit
+			}
+		}
+		opts.Limit = int64Pointer(limit)
+		filter, _ := p.Args["filter"].(map[string]any)
+		opts.Filters = c.fieldFilters(filter)
+
+		response, err := b.s.GetDocuments(b.projectID, c.schema.Name, c.schema, opts)
+		if err != nil {
+			return nil, graphQLError(err)
+		}
+
+		items := make([]any, len(response.Documents))
+		for i, doc := range response.Documents {
+			items[i] = graphQLDocument(doc)
+		}
+		var nextCursor any
+		if response.NextCursor != "" {
+			nextCursor = response.NextCursor
+		}
+		return map[string]any{
+			"items":      items,
+			"total":      response.Total,
+			"limit":      response.Limit,
+			"offset":     response.Offset,
+			"nextCursor": nextCursor,
+		}, nil
+	}
+}
+
+// fieldFilters переводит входной фильтр в фильтры по полям; значения передаются
+// строками и приводятся к типам схемы так же, как query-параметры REST
+func (c *graphQLCollection) fieldFilters(filter map[string]any) []model.FieldFilter {
+	names := make([]string, 0, len(filter))
+	for name := range filter {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var filters []model.FieldFilter
+	for _, name := range names {
+		f, ok := c.filters[name]
+		if !ok || filter[name] == nil {
+			continue
+		}
+		if values, ok := filter[name].([]any); ok {
+			for _, value := range values {
+				f.Raw = append(f.Raw, graphQLFilterValue(value))
+			}
+		} else {
+			f.Raw = []string{graphQLFilterValue(filter[name])}
+		}
+		filters = append(filters, f)
+	}
+	return filters
+}
+
+func graphQLFilterValue(value any) string {
+	if v, ok := value.(float64); ok {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// resolveReference раскрывает ссылку поля field на документ коллекции target
+func (b *graphQLBuilder) resolveReference(target, field string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		refs, _ := referenceIDs(sourceDocument(p)[field])
+		if len(refs) == 0 {
+			return nil, nil
+		}
+		load := b.request(p).loader.load(target, "id", refs)
+		return func() (any, error) {
+			related, err := load()
+			if err != nil {
+				return nil, err
+			}
+			if docs := related[uniqueKey(refs[0])]; len(docs) > 0 {
+				return docs[0], nil
+			}
+			// Ссылка на несуществующий документ раскрывается в null
+			return nil, nil
+		}, nil
+	}
+}
+
+// resolveReferences раскрывает массив ссылок поля field в документы коллекции target
+func (b *graphQLBuilder) resolveReferences(target, field string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		refs, _ := referenceIDs(sourceDocument(p)[field])
+		if len(refs) == 0 {
+			return []any{}, nil
+		}
+		load := b.request(p).loader.load(target, "id", refs)
+		return func() (any, error) {
+			related, err := load()
+			if err != nil {
+				return nil, err
+			}
+			items := []any{}
+			for _, ref := range refs {
+				if docs := related[uniqueKey(ref)]; len(docs) > 0 {
+					items = append(items, docs[0])
+				}
+			}
+			return items, nil
+		}, nil
+	}
+}
+
+// resolveChildren возвращает документы коллекции child, поле field которых ссылается на документ
+func (b *graphQLBuilder) resolveChildren(child, field string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		id := sourceDocument(p)["id"]
+		load := b.request(p).loader.load(child, field, []any{id})
+		return func() (any, error) {
+			related, err := load()
+			if err != nil {
+				return nil, err
+			}
+			items := []any{}
+			for _, doc := range related[uniqueKey(id)] {
+				items = append(items, doc)
+			}
+			return items, nil
+		}, nil
+	}
+}
+
+// graphQLLoader объединяет загрузку связанных документов: резолверы одного уровня
+// запроса возвращают отложенные значения, и первое из них загружает ссылки всех
+// резолверов одним запросом к коллекции (вместо запроса на каждый документ)
+type graphQLLoader struct {
+	s         *DocumentService
+	projectID int64
+	batches   map[string]*graphQLBatch
+}
+
+// graphQLBatch ссылки на документы коллекции по одному полю, загружаемые вместе
+type graphQLBatch struct {
+	collection string
+	field      string
+	values     []any
+	seen       map[string]bool
+	loaded     bool
+	related    map[string][]map[string]any // Документы по значению поля
+	err        error
+}
+
+// load добавляет значения в текущую порцию коллекции и возвращает функцию,
+// которая загружает порцию при первом вызове
+func (l *graphQLLoader) load(collection, field string, values []any) func() (map[string][]map[string]any, error) {
+	key := collection + "/" + field
+	batch := l.batches[key]
+	if batch == nil || batch.loaded {
+		batch = &graphQLBatch{collection: collection, field: field, seen: map[string]bool{}}
+		l.batches[key] = batch
+	}
+	for _, value := range values {
+		if k := uniqueKey(value); !batch.seen[k] {
+			batch.seen[k] = true
+			batch.values = append(batch.values, value)
+		}
+	}
+
+	return func() (map[string][]map[string]any, error) {
+		if !batch.loaded {
+			batch.loaded = true
+			batch.related, batch.err = l.fetch(batch)
+		}
+		return batch.related, batch.err
+	}
+}
+
+func (l *graphQLLoader) fetch(batch *graphQLBatch) (map[string][]map[string]any, error) {
+	docs, err := l.s.docRepo.FindByField(l.projectID, batch.collection, batch.field, batch.values)
+	if err != nil {
+		return nil, err
+	}
+
+	related := map[string][]map[string]any{}
+	for _, doc := range docs {
+		refs, _ := referenceIDs(doc.Data[batch.field])
+		for _, ref := range refs {
+			key := uniqueKey(ref)
+			related[key] = append(related[key], graphQLDocument(doc))
+		}
+	}
+	return related, nil
+}
+
+// graphQLDocument данные документа для резолверов. Документы без data.id
+// (созданные до автоинкремента) получают id из ObjectID
+func graphQLDocument(doc *model.MockDocument) map[string]any {
+	data := plainDocument(doc.Data)
+	if _, ok := data["id"]; !ok {
+		data["id"] = doc.ID.Hex()
+	}
+	return data
+}
+
+func sourceDocument(p graphql.ResolveParams) map[string]any {
+	doc, _ := p.Source.(map[string]any)
+	return doc
+}
+
+// resolveKey читает поле данных документа по исходному имени
+func resolveKey(key string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return sourceDocument(p)[key], nil
+	}
+}
+
+// graphQLScalar тип значения поля схемы
+func graphQLScalar(fieldType string) *graphql.Scalar {
+	switch fieldType {
+	case "number":
+		return graphql.Float
+	case "boolean":
+		return graphql.Boolean
+	case "date":
+		return dateTimeScalar
+	case "reference":
+		return graphql.ID
+	default:
+		return graphql.String
+	}
+}
+
+func graphQLOutputType(field models.FieldTemplate) graphql.Output {
+	if field.Type == "reference" && field.Cardinality == models.CardinalityMany {
+		return graphql.NewList(graphql.NewNonNull(graphql.ID))
+	}
+	return graphQLScalar(field.Type)
+}
+
+func graphQLInputType(field models.FieldTemplate) graphql.Input {
+	if field.Type == "reference" && field.Cardinality == models.CardinalityMany {
+		return graphql.NewList(graphql.NewNonNull(graphql.ID))
+	}
+	return graphQLScalar(field.Type)
+}
+
+// graphQLName приводит имя поля к имени GraphQL: недопустимые символы заменяются
+// на "_", перед цифрой в начале добавляется "_". Пустая строка - имя недопустимо
+// (имена на "__" зарезервированы GraphQL)
+func graphQLName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if strings.HasPrefix(b.String(), "__") {
+		return ""
+	}
+	return b.String()
+}
+
+// pascalName имя типа GraphQL из имени коллекции: order_items -> OrderItems
+func pascalName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(graphQLName(name), func(r rune) bool { return r == '_' }) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	result := b.String()
+	if result != "" && result[0] >= '0' && result[0] <= '9' {
+		result = "_" + result
+	}
+	return result
+}
+
+func lowerFirst(name string) string {
+	if name == "" || name[0] == '_' {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// graphQLCodedError ошибка резолвера с кодом в extensions.code
+type graphQLCodedError struct {
+	err        error
+	code       string
+	violations []model.FieldViolation
+}
+
+func (e *graphQLCodedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *graphQLCodedError) Extensions() map[string]any {
+	extensions := map[string]any{"code": e.code}
+	if e.violations != nil {
+		extensions["violations"] = e.violations
+	}
+	return extensions
+}
+
+// graphQLError добавляет к ошибке сервиса код, аналогичный статусу ответа REST
+func graphQLError(err error) error {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return &graphQLCodedError{err: err, code: "VALIDATION_FAILED", violations: validationErr.Violations}
+	case errors.Is(err, ErrInvalidQuery), errors.Is(err, ErrInvalidPatch):
+		return &graphQLCodedError{err: err, code: "BAD_USER_INPUT"}
+	case errors.Is(err, ErrConflict):
+		return &graphQLCodedError{err: err, code: "CONFLICT"}
+	}
+	return err
+}
+
+func int64Pointer(value int) *int64 {
+	v := int64(value)
+	return &v
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-mockingcode/models"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
+	"github.com/graphql-go/graphql/language/visitor"
+)
+
+// Ограничения запроса GraphQL. Каждый уровень связей (users { posts { user ... } })
+// выполняет запросы к MongoDB, поэтому глубина и число полей ограничены до выполнения
+const (
+	maxGraphQLQueryLength = 16 << 10 // Длина текста запроса
+	maxGraphQLDepth       = 10       // Вложенность полей, включая items страницы списка
+	maxGraphQLFields      = 500      // Поля запроса с раскрытыми фрагментами
+)
+
+// maxGraphQLSchemas сколько схем проектов хранится в кэше
+const maxGraphQLSchemas = 256
+
+// graphQLValidationRules стандартные правила GraphQL и ограничение сложности запроса
+var graphQLValidationRules = append(slices.Clone(graphql.SpecifiedRules), graphQLComplexityRule)
+
+// graphQLComplexityRule отклоняет операции глубже maxGraphQLDepth или с числом полей
+// больше maxGraphQLFields
+func graphQLComplexityRule(context *graphql.ValidationContext) *graphql.ValidationRuleInstance {
+	measure := &graphQLComplexity{
+		context:   context,
+		fragments: map[string][2]int{},
+		visiting:  map[string]bool{},
+	}
+	return &graphql.ValidationRuleInstance{
+		VisitorOpts: &visitor.VisitorOptions{
+			KindFuncMap: map[string]visitor.NamedVisitFuncs{
+				kinds.OperationDefinition: {
+					Kind: func(p visitor.VisitFuncParams) (string, any) {
+						operation, ok := p.Node.(*ast.OperationDefinition)
+						if !ok {
+							return visitor.ActionNoChange, nil
+						}
+						depth, fields := measure.selectionSet(operation.SelectionSet)
+						if depth > maxGraphQLDepth {
+							context.ReportError(gqlerrors.NewError(
+								fmt.Sprintf("query depth %d exceeds the limit of %d", depth, maxGraphQLDepth),
+								[]ast.Node{operation}, "", nil, []int{}, nil))
+						}
+						if fields > maxGraphQLFields {
+							context.ReportError(gqlerrors.NewError(
+								fmt.Sprintf("query selects %d fields, the limit is %d", fields, maxGraphQLFields),
+								[]ast.Node{operation}, "", nil, []int{}, nil))
+						}
+						return visitor.ActionSkip, nil
+					},
+				},
+			},
+		},
+	}
+}
+
+// graphQLComplexity считает глубину и число полей набора полей. Фрагменты считаются
+// один раз; циклы фрагментов отклоняет стандартное правило NoFragmentCycles
+type graphQLComplexity struct {
+	context   *graphql.ValidationContext
+	fragments map[string][2]int // Глубина и число полей фрагмента
+	visiting  map[string]bool
+}
+
+func (c *graphQLComplexity) selectionSet(set *ast.SelectionSet) (depth, fields int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var d, n int
+		switch s := selection.(type) {
+		case *ast.Field:
+			d, n = c.selectionSet(s.SelectionSet)
+			d, n = d+1, n+1
+		case *ast.InlineFragment:
+			d, n = c.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			d, n = c.fragment(s.Name.Value)
+		}
+		depth = max(depth, d)
+		// Число полей ограничено сверху, чтобы вложенные фрагменты не переполнили счетчик
+		fields = min(fields+n, maxGraphQLFields+1)
+	}
+	return depth, fields
+}
+
+func (c *graphQLComplexity) fragment(name string) (depth, fields int) {
+	if measured, ok := c.fragments[name]; ok {
+		return measured[0], measured[1]
+	}
+	fragment := c.context.Fragment(name)
+	if fragment == nil || c.visiting[name] {
+		return 0, 0
+	}
+	c.visiting[name] = true
+	depth, fields = c.selectionSet(fragment.SelectionSet)
+	c.visiting[name] = false
+	c.fragments[name] = [2]int{depth, fields}
+	return depth, fields
+}
+
+// graphQLSchemaCache схемы GraphQL проектов. Схема перестраивается, когда меняется
+// набор коллекций проекта или updated_at одной из них
+type graphQLSchemaCache struct {
+	mu      sync.Mutex
+	entries map[int64]graphQLSchemaEntry
+}
+
+type graphQLSchemaEntry struct {
+	version string
+	schema  graphql.Schema
+}
+
+func newGraphQLSchemaCache() *graphQLSchemaCache {
+	return &graphQLSchemaCache{entries: map[int64]graphQLSchemaEntry{}}
+}
+
+// get возвращает схему проекта из кэша или строит ее через build
+func (c *graphQLSchemaCache) get(projectID int64, collections []models.Collection, build func() (graphql.Schema, error)) (graphql.Schema, error) {
+	version := graphQLSchemaVersion(collections)
+
+	c.mu.Lock()
+	entry, ok := c.entries[projectID]
+	c.mu.Unlock()
+	if ok && entry.version == version {
+		return entry.schema, nil
+	}
+
+	schema, err := build()
+	if err != nil {
+		return schema, err
+	}
+
+	c.mu.Lock()
+	if _, ok := c.entries[projectID]; !ok && len(c.entries) >= maxGraphQLSchemas {
+		// Вытесняется произвольная схема: она будет построена заново при следующем запросе
+		for id := range c.entries {
+			delete(c.entries, id)
+			break
+		}
+	}
+	c.entries[projectID] = graphQLSchemaEntry{version: version, schema: schema}
+	c.mu.Unlock()
+	return schema, nil
+}
+
+// graphQLSchemaVersion отпечаток коллекций проекта, по которым строится схема
+func graphQLSchemaVersion(collections []models.Collection) string {
+	var version strings.Builder
+	for _, c := range collections {
+		fmt.Fprintf(&version, "%d:%s:%d;", c.ID, c.Name, c.UpdatedAt.UnixNano())
+	}
+	return version.String()
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// graphQLTestCollections пользователи и их посты: User.posts и Post.user дают
+// сколь угодно глубокие запросы
+var graphQLTestCollections = []models.Collection{
+	{ID: 1, Name: "users", Fields: []models.FieldTemplate{{Name: "name", Type: "string"}}},
+	{ID: 2, Name: "posts", Fields: []models.FieldTemplate{
+		{Name: "title", Type: "string"},
+		{Name: "userId", Type: "reference", Reference: "users"},
+	}},
+}
+
+// nestedQuery запрос глубины depth: users { items { posts { user { ... id } } } }
+func nestedQuery(depth int) string {
+	var query strings.Builder
+	query.WriteString("{ users { items { ")
+	for i := 0; i < depth-3; i++ {
+		if i%2 == 0 {
+			query.WriteString("posts { ")
+		} else {
+			query.WriteString("user { ")
+		}
+	}
+	query.WriteString("id")
+	query.WriteString(strings.Repeat(" }", depth))
+	return query.String()
+}
+
+// aliasedFields count полей id с псевдонимами
+func aliasedFields(count int) string {
+	fields := make([]string, count)
+	for i := range fields {
+		fields[i] = fmt.Sprintf("f%d: id", i)
+	}
+	return strings.Join(fields, " ")
+}
+
+// validateGraphQLQuery проверяет запрос так же, как ExecuteGraphQL, не выполняя его
+func validateGraphQLQuery(t *testing.T, query string) []string {
+	t.Helper()
+	schema, err := newGraphQLBuilder(&DocumentService{}, 1, graphQLTestCollections).schema()
+	if err != nil {
+		t.Fatalf("failed to build schema: %v", err)
+	}
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	var messages []string
+	for _, rules := range [][]graphql.ValidationRuleFn{{graphql.NoFragmentCyclesRule}, graphQLValidationRules} {
+		validation := graphql.ValidateDocument(&schema, document, rules)
+		for _, err := range validation.Errors {
+			messages = append(messages, err.Message)
+		}
+		if !validation.IsValid {
+			break
+		}
+	}
+	return messages
+}
+
+func TestGraphQLComplexityRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string // Подстрока ошибки, пусто - запрос допустим
+	}{
+		{
+			name:  "depth at the limit",
+			query: nestedQuery(maxGraphQLDepth),
+		},
+		{
+			name:    "depth over the limit",
+			query:   nestedQuery(maxGraphQLDepth + 1),
+			wantErr: fmt.Sprintf("query depth %d exceeds the limit of %d", maxGraphQLDepth+1, maxGraphQLDepth),
+		},
+		{
+			name:  "fields at the limit",
+			query: fmt.Sprintf("{ users { items { %s } } }", aliasedFields(maxGraphQLFields-2)),
+		},
+		{
+			name:    "fields over the limit",
+			query:   fmt.Sprintf("{ users { items { %s } } }", aliasedFields(maxGraphQLFields-1)),
+			wantErr: fmt.Sprintf("query selects %d fields, the limit is %d", maxGraphQLFields+1, maxGraphQLFields),
+		},
+		{
+			name: "fragment fields counted at every spread",
+			query: fmt.Sprintf(`{ users { items { ...F posts { user { ...F } } } } }
+				fragment F on User { %s }`, aliasedFields(maxGraphQLFields/2)),
+			wantErr: "the limit is 500",
+		},
+		{
+			name: "fragment depth added to spread depth",
+			query: `{ users { items { posts { ...P } } } }
+				fragment P on Post { user { posts { user { posts { user { posts { user { name } } } } } } } }`,
+			wantErr: fmt.Sprintf("exceeds the limit of %d", maxGraphQLDepth),
+		},
+		{
+			name:  "inline fragments do not add depth",
+			query: `{ users { items { ... on User { posts { ... on Post { title } } } } } }`,
+		},
+		{
+			name:    "every operation is measured",
+			query:   "query A { users { items { id } } } query B " + nestedQuery(maxGraphQLDepth+1),
+			wantErr: "exceeds the limit",
+		},
+		{
+			name:    "fragment cycle rejected before measuring",
+			query:   `{ users { items { ...A } } } fragment A on User { posts { user { ...B } } } fragment B on User { ...A }`,
+			wantErr: "Cannot spread fragment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := validateGraphQLQuery(t, tt.query)
+			if tt.wantErr == "" {
+				if len(messages) > 0 {
+					t.Fatalf("unexpected validation errors: %v", messages)
+				}
+				return
+			}
+			for _, message := range messages {
+				if strings.Contains(message, tt.wantErr) {
+					return
+				}
+			}
+			t.Errorf("validation errors %v, want one containing %q", messages, tt.wantErr)
+		})
+	}
+}
+
+func TestExecuteGraphQLRejectsLongQuery(t *testing.T) {
+	query := "{ users { items { id } } }" + strings.Repeat(" ", maxGraphQLQueryLength)
+	result := (&DocumentService{}).ExecuteGraphQL(context.Background(), 1, graphQLTestCollections, model.GraphQLRequest{Query: query}, "")
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "longer than") {
+		t.Errorf("ExecuteGraphQL() errors = %v, want query length error", result.Errors)
+	}
+}
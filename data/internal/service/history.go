@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+)
+
+// ParseAsOf разбирает момент времени ?as_of= (RFC 3339 или YYYY-MM-DD)
+func ParseAsOf(raw string) (time.Time, error) {
+	t, err := parseDate(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: as_of: %q is not a date (RFC 3339 or YYYY-MM-DD)", ErrInvalidQuery, raw)
+	}
+	return t, nil
+}
+
+// historyDocumentID приводит ID документа к виду, в котором он хранится в истории ("007" -> "7")
+func historyDocumentID(documentID string) string {
+	if numID, err := strconv.Atoi(documentID); err == nil {
+		return strconv.Itoa(numID)
+	}
+	return documentID
+}
+
+// GetHistory возвращает изменения документа, новые первыми. История доступна
+// и для удаленных документов
+func (s *DocumentService) GetHistory(projectID int64, collectionName, documentID string, opts model.QueryOptions) ([]model.DocumentChange, error) {
+	return s.docRepo.GetHistory(projectID, collectionName, historyDocumentID(documentID), opts)
+}
+
+// GetDocumentAsOf возвращает документ в состоянии на момент asOf или nil,
+// если документа тогда не было
+func (s *DocumentService) GetDocumentAsOf(projectID int64, collectionName, documentID string, asOf time.Time) (*model.MockDocument, error) {
+	return s.docRepo.GetDocumentAsOf(projectID, collectionName, historyDocumentID(documentID), asOf)
+}
+
+// GetDocumentsAsOf возвращает документы коллекции в состоянии на момент asOf.
+// Фильтры, поиск, сортировка и курсорная пагинация вместе с as_of не поддерживаются
+func (s *DocumentService) GetDocumentsAsOf(projectID int64, collectionName string, asOf time.Time, opts model.QueryOptions) (*model.DocumentsResponse, error) {
+	if len(opts.Filters) > 0 || opts.Search != "" || opts.Sort != "" || opts.Cursor != nil {
+		return nil, fmt.Errorf("%w: as_of supports only limit/offset and page/per_page", ErrInvalidQuery)
+	}
+	return s.docRepo.GetDocumentsAsOf(projectID, collectionName, asOf, opts)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/repository"
+)
+
+// maxIdempotencyKeyLength ограничение длины заголовка Idempotency-Key
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyService struct {
+	repo  *repository.IdempotencyRepository
+	ttl   time.Duration
+	lease time.Duration
+}
+
+// NewIdempotencyService создает сервис ключей идемпотентности. ttl - срок хранения
+// ответа, lease - срок резервирования ключа выполняющимся запросом: если сервис
+// упадет, не сохранив ответ, ключ освободится по истечении lease
+func NewIdempotencyService(repo *repository.IdempotencyRepository, ttl, lease time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		repo:  repo,
+		ttl:   ttl,
+		lease: lease,
+	}
+}
+
+// Begin резервирует ключ идемпотентности для запроса. Если запрос с этим ключом уже
+// выполнен, возвращает сохраненный ответ. Ключ, использованный с другим запросом,
+// дает ErrIdempotencyKeyReused, ключ выполняющегося запроса - ErrConflict
+func (s *IdempotencyService) Begin(projectID int64, key, method, path string, body []byte) (*model.IdempotencyRecord, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%w: Idempotency-Key must be at most %d characters", ErrInvalidQuery, maxIdempotencyKeyLength)
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", method, path)
+	hash.Write(body)
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	now := time.Now()
+	existing, err := s.repo.Reserve(&model.IdempotencyRecord{
+		ProjectID:   projectID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.lease),
+	})
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, fmt.Errorf("%w: key was used with a different request", ErrIdempotencyKeyReused)
+	}
+	if existing.Status == 0 {
+		return nil, fmt.Errorf("%w: request with this Idempotency-Key is still in progress", ErrConflict)
+	}
+	return existing, nil
+}
+
+// Complete сохраняет ответ на запрос для повторов с тем же ключом на срок ttl.
+// truncated - тело ответа не сохранено из-за размера, сохраняются код и заголовки
+func (s *IdempotencyService) Complete(projectID int64, key string, status int, headers map[string][]string, body []byte, truncated bool) error {
+	if truncated {
+		body = nil
+	}
+	return s.repo.Complete(&model.IdempotencyRecord{
+		ProjectID: projectID,
+		Key:       key,
+		Status:    status,
+		Headers:   headers,
+		Body:      body,
+		Truncated: truncated,
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+}
+
+// Release освобождает ключ запроса, который завершился ошибкой, чтобы его можно было повторить
+func (s *IdempotencyService) Release(projectID int64, key string) error {
+	return s.repo.Release(projectID, key)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+)
+
+// importBatchSize количество строк NDJSON, записываемых одним пакетом
+const importBatchSize = 500
+
+// maxNDJSONLine ограничение длины строки NDJSON при импорте
+const maxNDJSONLine = 1 << 20
+
+// StreamDocuments передает в fn документы коллекции, подходящие под фильтры, поиск
+// и сортировку opts, без пагинации. По умолчанию документы идут в порядке создания
+func (s *DocumentService) StreamDocuments(ctx context.Context, projectID int64, collectionName string, schema *models.Collection, opts model.QueryOptions, fn func(*model.MockDocument) error) error {
+	if opts.Sort == "" {
+		opts.Sort = model.MetaCreatedAt
+	}
+	if err := prepareQuery(schema, &opts); err != nil {
+		return err
+	}
+	return s.docRepo.StreamDocuments(ctx, projectID, collectionName, opts, fn)
+}
+
+// ImportNDJSON создает документы из NDJSON (один JSON-объект на строку), читая поток
+// пакетами по importBatchSize строк. Пустые строки пропускаются. В results попадают
+// только неуспешные строки, index - номер строки, начиная с 0. Строка длиннее
+// maxNDJSONLine останавливает импорт
+func (s *DocumentService) ImportNDJSON(projectID int64, collectionName string, schema *models.Collection, r io.Reader) (*model.BulkResponse, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	response := &model.BulkResponse{Results: []model.BulkItemResult{}}
+
+	var batch []model.BulkOperation
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		written, err := s.BulkWrite(projectID, collectionName, schema, batch, model.ChangeSourceImport)
+		if err != nil {
+			return fmt.Errorf("import stopped at line %d, %d documents created: %w", batch[0].Index, response.Created, err)
+		}
+		for i, result := range written.Results {
+			result.Index = batch[i].Index
+			result.Document = nil
+			if result.Error != "" {
+				response.Results = append(response.Results, result)
+			}
+		}
+		response.Created += written.Created
+		response.Failed += written.Failed
+		batch = batch[:0]
+		return nil
+	}
+
+	line := 0
+	for ; scanner.Scan(); line++ {
+		if data := bytes.TrimSpace(scanner.Bytes()); len(data) > 0 {
+			var doc map[string]any
+			if err := json.Unmarshal(data, &doc); err != nil || doc == nil {
+				response.Failed++
+				response.Results = append(response.Results, model.BulkItemResult{
+					Index:  line,
+					Op:     model.BulkCreate,
+					Status: http.StatusBadRequest,
+					Error:  "line is not a JSON object",
+				})
+			} else {
+				batch = append(batch, model.BulkOperation{Op: model.BulkCreate, Data: doc, Index: line})
+			}
+		}
+
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Строки до ошибки чтения записываются, как при остановке импорта на пакете
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%w: import stopped at line %d longer than %d bytes, %d documents created", ErrInvalidQuery, line, maxNDJSONLine, response.Created)
+		}
+		return nil, fmt.Errorf("failed to read NDJSON: %w", err)
+	}
+
+	if response.Created == 0 && response.Failed == 0 {
+		return nil, errors.New("NDJSON has no documents")
+	}
+	return response, nil
+}
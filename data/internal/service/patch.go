@@ -0,0 +1,342 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// DocumentPatch изменяет данные документа. Получает копию data и возвращает новое значение
+type DocumentPatch func(data map[string]any) (map[string]any, error)
+
+// applyPatch применяет патч к копии данных документа. id документа патчем не изменяется
+func applyPatch(current map[string]any, patch DocumentPatch) (map[string]any, error) {
+	data, err := patch(plainDocument(current))
+	if err != nil {
+		return nil, err
+	}
+	if id, hasID := current["id"]; hasID {
+		data["id"] = id
+	}
+	return data, nil
+}
+
+// MergePatch возвращает патч по RFC 7396 (application/merge-patch+json):
+// null удаляет поле, объекты сливаются рекурсивно, остальные значения заменяются
+func MergePatch(patch map[string]any) DocumentPatch {
+	return func(data map[string]any) (map[string]any, error) {
+		return mergeObjects(data, patch), nil
+	}
+}
+
+func mergeObjects(target, patch map[string]any) map[string]any {
+	if target == nil {
+		target = make(map[string]any)
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]any); ok {
+			targetObject, _ := target[key].(map[string]any)
+			target[key] = mergeObjects(targetObject, patchObject)
+			continue
+		}
+		target[key] = value
+	}
+	return target
+}
+
+// JSONPatch возвращает патч по RFC 6902 (application/json-patch+json).
+// Операции применяются последовательно, ошибка любой из них отменяет весь патч
+func JSONPatch(operations []model.PatchOperation) DocumentPatch {
+	return func(data map[string]any) (map[string]any, error) {
+		var doc any = data
+		for i, op := range operations {
+			var err error
+			doc, err = applyOperation(doc, op)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+			}
+		}
+
+		result, ok := doc.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: document must remain an object", ErrInvalidPatch)
+		}
+		return result, nil
+	}
+}
+
+func applyOperation(doc any, op model.PatchOperation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: value is required", ErrInvalidPatch)
+		}
+		var value any
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: invalid value", ErrInvalidPatch)
+		}
+
+		switch op.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if doc, _, err = removeValue(doc, path); err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, value)
+		default:
+			current, err := getValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(current, value) {
+				return nil, fmt.Errorf("%w: test failed", ErrConflict)
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		doc, _, err = removeValue(doc, path)
+		return doc, err
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			return addValue(doc, path, plainValue(value))
+		}
+		if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return nil, fmt.Errorf("%w: cannot move a value into its own child", ErrInvalidPatch)
+		}
+		if doc, _, err = removeValue(doc, from); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901) на токены
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: invalid path %q", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func getValue(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			value, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: path not found", ErrInvalidPatch)
+			}
+			node = value
+		case []any:
+			index, err := arrayIndex(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("%w: path not found", ErrInvalidPatch)
+		}
+	}
+	return node, nil
+}
+
+// addValue добавляет значение по пути и возвращает измененный узел
+// (для массивов вставка создает новый срез)
+func addValue(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token, rest := path[0], path[1:]
+	switch n := node.(type) {
+	case map[string]any:
+		if len(rest) == 0 {
+			n[token] = value
+			return n, nil
+		}
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("%w: path not found", ErrInvalidPatch)
+		}
+		updated, err := addValue(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+
+	case []any:
+		if len(rest) == 0 {
+			if token == "-" {
+				return append(n, value), nil
+			}
+			index, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, err
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		index, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := addValue(n[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("%w: path not found", ErrInvalidPatch)
+	}
+}
+
+// removeValue удаляет значение по пути и возвращает измененный узел и удаленное значение
+func removeValue(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+
+	token, rest := path[0], path[1:]
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: path not found", ErrInvalidPatch)
+		}
+		if len(rest) == 0 {
+			delete(n, token)
+			return n, child, nil
+		}
+		updated, removed, err := removeValue(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = updated
+		return n, removed, nil
+
+	case []any:
+		index, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := n[index]
+			return append(n[:index], n[index+1:]...), removed, nil
+		}
+		updated, removed, err := removeValue(n[index], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[index] = updated
+		return n, removed, nil
+
+	default:
+		return nil, nil, fmt.Errorf("%w: path not found", ErrInvalidPatch)
+	}
+}
+
+func arrayIndex(token string, maxIndex int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > maxIndex || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	return index, nil
+}
+
+// jsonEqual сравнивает значения так, как они выглядят в JSON (числа разных типов равны)
+func jsonEqual(a, b any) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	var aValue, bValue any
+	json.Unmarshal(aJSON, &aValue)
+	json.Unmarshal(bJSON, &bValue)
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// plainValue возвращает глубокую копию значения, заменяя bson.D и bson.A
+// на map[string]any и []any. Остальные типы (даты, числа) сохраняются
+func plainValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return plainDocument(v)
+	case bson.M:
+		return plainDocument(v)
+	case bson.D:
+		doc := make(map[string]any, len(v))
+		for _, e := range v {
+			doc[e.Key] = plainValue(e.Value)
+		}
+		return doc
+	case []any:
+		return plainArray(v)
+	case bson.A:
+		return plainArray(v)
+	default:
+		return value
+	}
+}
+
+func plainDocument(doc map[string]any) map[string]any {
+	result := make(map[string]any, len(doc))
+	for key, value := range doc {
+		result[key] = plainValue(value)
+	}
+	return result
+}
+
+func plainArray(items []any) []any {
+	result := make([]any, len(items))
+	for i, item := range items {
+		result[i] = plainValue(item)
+	}
+	return result
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// jsonObject разбирает JSON-объект теста
+func jsonObject(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var value map[string]any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		t.Fatalf("invalid test JSON %s: %v", raw, err)
+	}
+	return value
+}
+
+// patchOperations разбирает операции JSON Patch теста
+func patchOperations(t *testing.T, raw string) []model.PatchOperation {
+	t.Helper()
+	var operations []model.PatchOperation
+	if err := json.Unmarshal([]byte(raw), &operations); err != nil {
+		t.Fatalf("invalid test JSON Patch %s: %v", raw, err)
+	}
+	return operations
+}
+
+// Примеры из приложения A RFC 7396
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{name: "replace value", target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "add member", target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{name: "null removes member", target: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{name: "null keeps other members", target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{name: "array replaced by value", target: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "value replaced by array", target: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{name: "nested objects merged", target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{name: "arrays not merged", target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{name: "object replaces scalar", target: `{"a":"foo"}`, patch: `{"a":{"b":"c"}}`, want: `{"a":{"b":"c"}}`},
+		{name: "null inside new object dropped", target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+		{name: "empty patch", target: `{"a":"b"}`, patch: `{}`, want: `{"a":"b"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch(jsonObject(t, tt.patch))(jsonObject(t, tt.target))
+			if err != nil {
+				t.Fatalf("MergePatch() error = %v", err)
+			}
+			if want := jsonObject(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("MergePatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// Примеры из приложения A RFC 6902
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{name: "add object member", target: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, want: `{"baz":"qux","foo":"bar"}`},
+		{name: "add array element", target: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, want: `{"foo":["bar","qux","baz"]}`},
+		{name: "append to array", target: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, want: `{"foo":["bar",["abc","def"]]}`},
+		{name: "add replaces existing member", target: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/foo","value":1}]`, want: `{"foo":1}`},
+		{name: "remove object member", target: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, want: `{"foo":"bar"}`},
+		{name: "remove array element", target: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, want: `{"foo":["bar","baz"]}`},
+		{name: "replace value", target: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, want: `{"baz":"boo","foo":"bar"}`},
+		{name: "move value", target: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, want: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "move array element", target: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, want: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "copy value", target: `{"foo":{"bar":1}}`, patch: `[{"op":"copy","from":"/foo","path":"/baz"}]`, want: `{"foo":{"bar":1},"baz":{"bar":1}}`},
+		{name: "test passes", target: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, want: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "escaped path", target: `{"a/b":1,"m~n":2}`, patch: `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, want: `{"a/b":3}`},
+		{name: "replace whole document", target: `{"a":1}`, patch: `[{"op":"replace","path":"","value":{"b":2}}]`, want: `{"b":2}`},
+
+		{name: "test fails", target: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, wantErr: ErrConflict},
+		{name: "add to missing parent", target: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, wantErr: ErrInvalidPatch},
+		{name: "remove missing member", target: `{"foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, wantErr: ErrInvalidPatch},
+		{name: "index out of range", target: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/2","value":"qux"}]`, wantErr: ErrInvalidPatch},
+		{name: "index with leading zero", target: `{"foo":["bar","baz"]}`, patch: `[{"op":"remove","path":"/foo/01"}]`, wantErr: ErrInvalidPatch},
+		{name: "move into own child", target: `{"foo":{"bar":1}}`, patch: `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, wantErr: ErrInvalidPatch},
+		{name: "remove whole document", target: `{"foo":"bar"}`, patch: `[{"op":"remove","path":""}]`, wantErr: ErrInvalidPatch},
+		{name: "document must stay an object", target: `{"foo":"bar"}`, patch: `[{"op":"replace","path":"","value":[1]}]`, wantErr: ErrInvalidPatch},
+		{name: "value required", target: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz"}]`, wantErr: ErrInvalidPatch},
+		{name: "path without slash", target: `{"foo":"bar"}`, patch: `[{"op":"remove","path":"foo"}]`, wantErr: ErrInvalidPatch},
+		{name: "unknown operation", target: `{"foo":"bar"}`, patch: `[{"op":"merge","path":"/foo","value":1}]`, wantErr: ErrInvalidPatch},
+		{name: "later operation fails", target: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":1},{"op":"remove","path":"/missing"}]`, wantErr: ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch(patchOperations(t, tt.patch))(jsonObject(t, tt.target))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("JSONPatch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONPatch() error = %v", err)
+			}
+			if want := jsonObject(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("JSONPatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		current map[string]any
+		patch   func(t *testing.T) DocumentPatch
+		want    map[string]any
+	}{
+		{
+			name:    "merge patch cannot change id",
+			current: map[string]any{"id": int64(1), "name": "a"},
+			patch: func(t *testing.T) DocumentPatch {
+				return MergePatch(jsonObject(t, `{"id":2,"name":"b"}`))
+			},
+			want: map[string]any{"id": int64(1), "name": "b"},
+		},
+		{
+			name:    "merge patch cannot remove id",
+			current: map[string]any{"id": "a1", "name": "a"},
+			patch: func(t *testing.T) DocumentPatch {
+				return MergePatch(jsonObject(t, `{"id":null}`))
+			},
+			want: map[string]any{"id": "a1", "name": "a"},
+		},
+		{
+			name:    "json patch cannot remove id",
+			current: map[string]any{"id": int64(1), "name": "a"},
+			patch: func(t *testing.T) DocumentPatch {
+				return JSONPatch(patchOperations(t, `[{"op":"remove","path":"/id"}]`))
+			},
+			want: map[string]any{"id": int64(1), "name": "a"},
+		},
+		{
+			name:    "json patch replacing the document keeps id",
+			current: map[string]any{"id": int64(1), "name": "a"},
+			patch: func(t *testing.T) DocumentPatch {
+				return JSONPatch(patchOperations(t, `[{"op":"replace","path":"","value":{"title":"b"}}]`))
+			},
+			want: map[string]any{"id": int64(1), "title": "b"},
+		},
+		{
+			name:    "stored bson documents are patched as objects",
+			current: map[string]any{"id": int64(1), "address": bson.D{{Key: "city", Value: "Oslo"}, {Key: "zip", Value: "0150"}}},
+			patch: func(t *testing.T) DocumentPatch {
+				return MergePatch(jsonObject(t, `{"address":{"zip":null}}`))
+			},
+			want: map[string]any{"id": int64(1), "address": map[string]any{"city": "Oslo"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatch(tt.current, tt.patch(t))
+			if err != nil {
+				t.Fatalf("applyPatch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyPatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPatchKeepsCurrentData(t *testing.T) {
+	current := map[string]any{"id": int64(1), "tags": []any{"a", "b"}, "meta": map[string]any{"views": 1}}
+	patch := JSONPatch(patchOperations(t, `[{"op":"remove","path":"/tags/0"},{"op":"replace","path":"/meta/views","value":2},{"op":"test","path":"/id","value":3}]`))
+
+	if _, err := applyPatch(current, patch); !errors.Is(err, ErrConflict) {
+		t.Fatalf("applyPatch() error = %v, want ErrConflict", err)
+	}
+	want := map[string]any{"id": int64(1), "tags": []any{"a", "b"}, "meta": map[string]any{"views": 1}}
+	if !reflect.DeepEqual(current, want) {
+		t.Errorf("current data changed to %v", current)
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+)
+
+// prepareFilters приводит значения фильтров к типам полей схемы коллекции.
+// Для полей без схемы тип определяется по виду значения
+func prepareFilters(schema *models.Collection, filters []model.FieldFilter) ([]model.FieldFilter, error) {
+	types := schemaFieldTypes(schema)
+
+	prepared := make([]model.FieldFilter, 0, len(filters))
+	for _, f := range filters {
+		// Имя поля схемы само может оканчиваться на суффикс оператора (например, "is_in")
+		if f.Operator != model.FilterEq {
+			if _, ok := types[f.Field+"_"+f.Operator]; ok {
+				f.Field = f.Field + "_" + f.Operator
+				f.Operator = model.FilterEq
+			}
+		}
+
+		values, err := coerceFilterValues(f, types[f.Field])
+		if err != nil {
+			return nil, err
+		}
+		f.Values = values
+		prepared = append(prepared, f)
+	}
+
+	return prepared, nil
+}
+
+func coerceFilterValues(f model.FieldFilter, fieldType string) ([]any, error) {
+	if len(f.Raw) == 0 {
+		return nil, fmt.Errorf("%w: filter %s has no value", ErrInvalidQuery, f.Field)
+	}
+	last := f.Raw[len(f.Raw)-1]
+
+	switch f.Operator {
+	case model.FilterLike:
+		values := make([]any, 0, len(f.Raw))
+		for _, raw := range f.Raw {
+			if _, err := regexp.Compile(raw); err != nil {
+				return nil, fmt.Errorf("%w: %s_like: invalid pattern %q", ErrInvalidQuery, f.Field, raw)
+			}
+			values = append(values, raw)
+		}
+		return values, nil
+
+	case model.FilterExists:
+		exists, err := strconv.ParseBool(last)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s_exists: %q is not a boolean", ErrInvalidQuery, f.Field, last)
+		}
+		return []any{exists}, nil
+
+	case model.FilterGt, model.FilterGte, model.FilterLt, model.FilterLte:
+		if fieldType == "" {
+			return []any{inferValue(last)}, nil
+		}
+		value, err := coerceValue(f.Field, last, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		return []any{value}, nil
+	}
+
+	// eq, ne, in - список допустимых значений
+	raws := f.Raw
+	if f.Operator == model.FilterIn {
+		raws = splitList(f.Raw)
+	}
+
+	values := make([]any, 0, len(raws))
+	for _, raw := range raws {
+		if fieldType == "" {
+			values = append(values, inferValues(raw)...)
+			continue
+		}
+		value, err := coerceValue(f.Field, raw, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// coerceValue приводит строковое значение к типу поля схемы
+func coerceValue(field, raw, fieldType string) (any, error) {
+	switch fieldType {
+	case "number":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %q is not a number", ErrInvalidQuery, field, raw)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %q is not a boolean", ErrInvalidQuery, field, raw)
+		}
+		return v, nil
+	case "date":
+		v, err := parseDate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %q is not a date", ErrInvalidQuery, field, raw)
+		}
+		return v, nil
+	case "reference":
+		// id документов - числа, для документов с ObjectID - строки
+		return inferValue(raw), nil
+	default:
+		return raw, nil
+	}
+}
+
+// inferValue определяет тип значения для поля без схемы (число или строка)
+func inferValue(raw string) any {
+	if v, err := strconv.ParseFloat(raw, 64); err == nil {
+		return v
+	}
+	return raw
+}
+
+// inferValues возвращает варианты значения для сравнения на равенство
+// в поле без схемы: исходную строку и приведенное число или bool
+func inferValues(raw string) []any {
+	values := []any{raw}
+	if v, err := strconv.ParseFloat(raw, 64); err == nil {
+		values = append(values, v)
+	} else if v, err := strconv.ParseBool(raw); err == nil {
+		values = append(values, v)
+	}
+	return values
+}
+
+func parseDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+// splitList разбивает значения вида "a,b,c" на отдельные элементы
+func splitList(raws []string) []string {
+	var items []string
+	for _, raw := range raws {
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// prepareSort разбирает сортировку вида "-price,name": минус перед полем задает
+// убывающий порядок, order задает направление для ключей без префикса.
+// При наличии схемы поля проверяются по ней
+func prepareSort(schema *models.Collection, sort, order string) ([]model.SortKey, error) {
+	if sort == "" {
+		return nil, nil
+	}
+
+	defaultDesc := false
+	switch strings.ToLower(order) {
+	case "", "asc":
+	case "desc":
+		defaultDesc = true
+	default:
+		return nil, fmt.Errorf("%w: order must be asc or desc", ErrInvalidQuery)
+	}
+
+	types := schemaFieldTypes(schema)
+
+	var keys []model.SortKey
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+
+		key := model.SortKey{Field: part, Desc: defaultDesc}
+		switch {
+		case strings.HasPrefix(part, "-"):
+			key = model.SortKey{Field: part[1:], Desc: true}
+		case strings.HasPrefix(part, "+"):
+			key = model.SortKey{Field: part[1:], Desc: false}
+		}
+
+		if key.Field == "" {
+			return nil, fmt.Errorf("%w: empty sort key", ErrInvalidQuery)
+		}
+
+		if schema != nil && !key.IsMeta() && key.Field != "id" {
+			// Для вложенных полей (address.city) проверяем поле верхнего уровня
+			root, _, _ := strings.Cut(key.Field, ".")
+			if _, ok := types[root]; !ok {
+				return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, key.Field)
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// searchFields возвращает поля для поиска ?q=: помеченные searchable в схеме,
+// иначе все строковые поля схемы. Без схемы - nil (поиск по всем строковым полям)
+func searchFields(schema *models.Collection) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var searchable, stringFields []string
+	for _, field := range schema.Fields {
+		if field.Type != "string" && field.Type != "" {
+			continue
+		}
+		stringFields = append(stringFields, field.Name)
+		if field.Searchable {
+			searchable = append(searchable, field.Name)
+		}
+	}
+
+	if len(searchable) > 0 {
+		return searchable
+	}
+	return stringFields
+}
+
+// schemaFieldTypes возвращает типы полей схемы коллекции по имени поля
+func schemaFieldTypes(schema *models.Collection) map[string]string {
+	types := make(map[string]string)
+	if schema == nil {
+		return types
+	}
+	for _, field := range schema.Fields {
+		types[field.Name] = field.Type
+	}
+	return types
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SchemaLoader загружает схему коллекции проекта (nil для коллекций без схемы)
+type SchemaLoader func(collectionName string) (*models.Collection, error)
+
+// relation связь между коллекциями, раскрываемая через ?_expand или ?_embed
+type relation struct {
+	key        string // Ключ, под которым связанные данные добавляются в документ
+	collection string // Коллекция связанных документов
+	field      string // Поле со ссылкой: в документе (expand) или в связанной коллекции (embed)
+	many       bool   // Поле expand хранит массив ссылок
+}
+
+// IncludeRelated добавляет в документы связанные документы по data.id:
+// expand раскрывает поля reference документа (posts?_expand=user),
+// embed встраивает документы другой коллекции, ссылающиеся на документ (posts?_embed=comments)
+func (s *DocumentService) IncludeRelated(projectID int64, collectionName string, schema *models.Collection, docs []*model.MockDocument, expand, embed []string, loadSchema SchemaLoader) error {
+	for _, name := range expand {
+		rel, err := expandRelation(schema, name)
+		if err != nil {
+			return err
+		}
+		if err := models.ValidateCollectionName(rel.collection); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		if err := s.expand(projectID, docs, rel); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range embed {
+		if err := models.ValidateCollectionName(name); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		childSchema, err := loadSchema(name)
+		if err != nil {
+			return err
+		}
+		if err := s.embed(projectID, docs, embedRelation(collectionName, name, childSchema)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandRelation находит поле reference по имени поля (userId) или имени связи (user).
+// Без описанного поля действует соглашение json-server: user -> поле userId, коллекция users
+func expandRelation(schema *models.Collection, name string) (relation, error) {
+	types := schemaFieldTypes(schema)
+
+	if schema != nil {
+		for _, field := range schema.Fields {
+			if field.Type != "reference" || field.Reference == "" {
+				continue
+			}
+			if field.Name == name || relationKey(field.Name) == name {
+				return relation{
+					key:        relationKey(field.Name),
+					collection: field.Reference,
+					field:      field.Name,
+					many:       field.Cardinality == models.CardinalityMany,
+				}, nil
+			}
+		}
+	}
+
+	if _, ok := types[name+"Id"]; schema == nil || ok {
+		return relation{key: name, collection: plural(name), field: name + "Id"}, nil
+	}
+	return relation{}, fmt.Errorf("%w: unknown relation %q", ErrInvalidQuery, name)
+}
+
+// embedRelation находит в дочерней коллекции поле reference на родительскую.
+// Без описанного поля действует соглашение json-server: posts -> поле postId
+func embedRelation(parent, child string, childSchema *models.Collection) relation {
+	if childSchema != nil {
+		for _, field := range childSchema.Fields {
+			if field.Type == "reference" && field.Reference == parent {
+				return relation{key: child, collection: child, field: field.Name}
+			}
+		}
+	}
+	return relation{key: child, collection: child, field: singular(parent) + "Id"}
+}
+
+// plural образует множественное число для соглашения об именах коллекций: user -> users, category -> categories
+func plural(name string) string {
+	switch {
+	case len(name) > 1 && strings.HasSuffix(name, "y") && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	default:
+		return name + "s"
+	}
+}
+
+// singular обратное к plural преобразование: posts -> post, categories -> category
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	default:
+		return strings.TrimSuffix(name, "s")
+	}
+}
+
+// relationKey ключ раскрытой связи: userId -> user, tagIds -> tags, author -> author
+func relationKey(field string) string {
+	for _, suffix := range []string{"Ids", "_ids"} {
+		if name, ok := strings.CutSuffix(field, suffix); ok && name != "" {
+			return plural(name)
+		}
+	}
+	for _, suffix := range []string{"Id", "_id"} {
+		if name, ok := strings.CutSuffix(field, suffix); ok && name != "" {
+			return name
+		}
+	}
+	return field
+}
+
+func (s *DocumentService) expand(projectID int64, docs []*model.MockDocument, rel relation) error {
+	var ids []any
+	for _, doc := range docs {
+		refs, _ := referenceIDs(doc.Data[rel.field])
+		ids = append(ids, refs...)
+	}
+
+	related := make(map[string]model.CleanDocument)
+	if len(ids) > 0 {
+		found, err := s.docRepo.FindByField(projectID, rel.collection, "id", ids)
+		if err != nil {
+			return err
+		}
+		for _, doc := range found {
+			related[uniqueKey(doc.Data["id"])] = doc.ToClean()
+		}
+	}
+
+	for _, doc := range docs {
+		refs, isArray := referenceIDs(doc.Data[rel.field])
+		if !rel.many && !isArray {
+			// Ссылка на несуществующий документ раскрывается в null
+			var item any
+			if len(refs) > 0 {
+				if match, ok := related[uniqueKey(refs[0])]; ok {
+					item = match
+				}
+			}
+			doc.Data[rel.key] = item
+			continue
+		}
+
+		items := []any{}
+		for _, ref := range refs {
+			if match, ok := related[uniqueKey(ref)]; ok {
+				items = append(items, match)
+			}
+		}
+		doc.Data[rel.key] = items
+	}
+
+	return nil
+}
+
+func (s *DocumentService) embed(projectID int64, docs []*model.MockDocument, rel relation) error {
+	var ids []any
+	for _, doc := range docs {
+		if id, ok := doc.Data["id"]; ok {
+			ids = append(ids, id)
+		}
+	}
+
+	children := make(map[string][]any)
+	if len(ids) > 0 {
+		found, err := s.docRepo.FindByField(projectID, rel.collection, rel.field, ids)
+		if err != nil {
+			return err
+		}
+		for _, child := range found {
+			refs, _ := referenceIDs(child.Data[rel.field])
+			for _, ref := range refs {
+				key := uniqueKey(ref)
+				children[key] = append(children[key], child.ToClean())
+			}
+		}
+	}
+
+	for _, doc := range docs {
+		items := children[uniqueKey(doc.Data["id"])]
+		if items == nil {
+			items = []any{}
+		}
+		doc.Data[rel.key] = items
+	}
+
+	return nil
+}
+
+// referenceIDs возвращает ссылки из значения поля reference и признак массива
+func referenceIDs(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case []any:
+		return v, true
+	case bson.A:
+		return v, true
+	default:
+		return []any{v}, false
+	}
+}
+
+// GetChildDocuments возвращает документы дочерней коллекции, ссылающиеся на родительский
+// документ: /users/1/posts - посты с userId = 1. Остальные опции запроса применяются как обычно
+func (s *DocumentService) GetChildDocuments(projectID int64, parentCollection string, parent *model.MockDocument, childCollection string, childSchema *models.Collection, opts model.QueryOptions) (*model.DocumentsResponse, error) {
+	rel := embedRelation(parentCollection, childCollection, childSchema)
+	opts.Filters = append(opts.Filters, model.FieldFilter{
+		Field:    rel.field,
+		Operator: model.FilterEq,
+		Raw:      []string{fmt.Sprint(documentRef(parent))},
+	})
+	return s.GetDocuments(projectID, childCollection, childSchema, opts)
+}
+
+// CreateChildDocument создает документ дочерней коллекции со ссылкой на родительский документ
+func (s *DocumentService) CreateChildDocument(projectID int64, parentCollection string, parent *model.MockDocument, childCollection string, childSchema *models.Collection, data map[string]any, source string) (*model.MockDocument, error) {
+	rel := embedRelation(parentCollection, childCollection, childSchema)
+	ref := documentRef(parent)
+
+	if isManyReference(childSchema, rel.field) {
+		refs, _ := referenceIDs(data[rel.field])
+		linked := slices.ContainsFunc(refs, func(v any) bool { return uniqueKey(v) == uniqueKey(ref) })
+		if !linked {
+			refs = append(refs, ref)
+		}
+		data[rel.field] = refs
+	} else {
+		data[rel.field] = ref
+	}
+
+	return s.CreateDocument(projectID, childCollection, childSchema, data, source)
+}
+
+// documentRef значение, которым на документ ссылаются другие документы (data.id,
+// для документов без него - ObjectID)
+func documentRef(doc *model.MockDocument) any {
+	if id, ok := doc.Data["id"]; ok {
+		return id
+	}
+	return doc.ID.Hex()
+}
+
+func isManyReference(schema *models.Collection, fieldName string) bool {
+	if schema == nil {
+		return false
+	}
+	for _, field := range schema.Fields {
+		if field.Name == fieldName {
+			return field.Type == "reference" && field.Cardinality == models.CardinalityMany
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+)
+
+// MatchRule возвращает первое правило, подходящее по методу, все условия которого
+// выполнены для запроса. Возвращает nil, если запрос обрабатывается как обычно
+func MatchRule(rules []models.ResponseRule, req RequestData) *models.ResponseRule {
+	for i := range rules {
+		rule := &rules[i]
+		if len(rule.Methods) > 0 && !slices.ContainsFunc(rule.Methods, func(m string) bool { return strings.EqualFold(m, req.Method) }) {
+			continue
+		}
+		if !slices.ContainsFunc(rule.When, func(c models.RuleCondition) bool { return !conditionHolds(c, req) }) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// conditionHolds проверяет условие правила для значения из запроса
+func conditionHolds(c models.RuleCondition, req RequestData) bool {
+	value, ok := conditionValue(c, req)
+
+	switch c.Operator {
+	case model.FilterExists:
+		want, err := strconv.ParseBool(c.Value)
+		if err != nil {
+			want = true
+		}
+		return ok == want
+	case model.FilterNe:
+		return !ok || !valuesEqual(value, c.Value)
+	}
+
+	if !ok {
+		return false
+	}
+
+	switch c.Operator {
+	case model.FilterGt, model.FilterGte, model.FilterLt, model.FilterLte:
+		actual, okActual := numberValue(value)
+		if s, isString := value.(string); isString {
+			actual, okActual = parseNumber(s)
+		}
+		expected, okExpected := parseNumber(c.Value)
+		if !okActual || !okExpected {
+			return false
+		}
+		switch c.Operator {
+		case model.FilterGt:
+			return actual > expected
+		case model.FilterGte:
+			return actual >= expected
+		case model.FilterLt:
+			return actual < expected
+		default:
+			return actual <= expected
+		}
+	case model.FilterIn:
+		return slices.ContainsFunc(strings.Split(c.Value, ","), func(v string) bool {
+			return valuesEqual(value, strings.TrimSpace(v))
+		})
+	case model.FilterLike:
+		re, err := regexp.Compile(c.Value)
+		return err == nil && re.MatchString(fmt.Sprint(value))
+	default:
+		return valuesEqual(value, c.Value)
+	}
+}
+
+// conditionValue достает из запроса значение для условия
+func conditionValue(c models.RuleCondition, req RequestData) (any, bool) {
+	switch c.Source {
+	case models.RuleSourceHeader:
+		v, ok := req.Headers[http.CanonicalHeaderKey(c.Field)]
+		return v, ok
+	case models.RuleSourceQuery:
+		v, ok := req.Query[c.Field]
+		return v, ok
+	case models.RuleSourceParam:
+		v, ok := req.Params[c.Field]
+		return v, ok
+	case models.RuleSourceBody:
+		return bodyValue(req.Body, c.Field)
+	default:
+		return nil, false
+	}
+}
+
+// bodyValue находит значение в JSON-теле по пути через точку: customer.email, items.0.price
+func bodyValue(body any, path string) (any, bool) {
+	current := body
+	for _, key := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			current = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// valuesEqual сравнивает значение из запроса со строкой из условия: числа - как числа
+func valuesEqual(value any, expected string) bool {
+	if actual, ok := numberValue(value); ok {
+		number, ok := parseNumber(expected)
+		return ok && actual == number
+	}
+	return fmt.Sprint(value) == expected
+}
+
+func parseNumber(raw string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	return v, err == nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/repository"
+	"github.com/go-mockingcode/models"
+)
+
+// snapshotNamePattern имя снимка используется в пути запроса
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// CollectionSource возвращает коллекции проекта (project service)
+type CollectionSource interface {
+	GetCollections(projectID int64) ([]models.Collection, error)
+}
+
+type SnapshotService struct {
+	source CollectionSource
+	repo   *repository.SnapshotRepository
+}
+
+func NewSnapshotService(source CollectionSource, repo *repository.SnapshotRepository) *SnapshotService {
+	return &SnapshotService{
+		source: source,
+		repo:   repo,
+	}
+}
+
+// collectionNames возвращает имена коллекций проекта, описанных в project service
+func (s *SnapshotService) collectionNames(projectID int64) ([]string, error) {
+	collections, err := s.source.GetCollections(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project collections: %w", err)
+	}
+	names := make([]string, 0, len(collections))
+	for _, c := range collections {
+		if models.ValidateCollectionName(c.Name) == nil {
+			names = append(names, c.Name)
+		}
+	}
+	return names, nil
+}
+
+// CreateSnapshot сохраняет текущее состояние коллекций проекта под именем name
+func (s *SnapshotService) CreateSnapshot(projectID int64, name string) (*model.Snapshot, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return nil, errors.New("snapshot name must be 1-64 letters, digits, '.', '_' or '-' and start with a letter or digit")
+	}
+
+	names, err := s.collectionNames(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.repo.Create(projectID, name, names)
+	if errors.Is(err, repository.ErrSnapshotExists) {
+		return nil, fmt.Errorf("%w: snapshot %q already exists", ErrConflict, name)
+	}
+	return snapshot, err
+}
+
+// ListSnapshots возвращает снимки проекта
+func (s *SnapshotService) ListSnapshots(projectID int64) ([]model.Snapshot, error) {
+	return s.repo.List(projectID)
+}
+
+// GetSnapshot возвращает снимок по имени или nil, если его нет
+func (s *SnapshotService) GetSnapshot(projectID int64, name string) (*model.Snapshot, error) {
+	return s.repo.Get(projectID, name)
+}
+
+// DeleteSnapshot удаляет снимок. Возвращает false, если снимка нет
+func (s *SnapshotService) DeleteSnapshot(projectID int64, name string) (bool, error) {
+	return s.repo.Delete(projectID, name)
+}
+
+// RestoreSnapshot возвращает все коллекции проекта к состоянию снимка.
+// Возвращает nil, если снимка нет
+func (s *SnapshotService) RestoreSnapshot(projectID int64, name string) (*model.Snapshot, error) {
+	snapshot, err := s.repo.Get(projectID, name)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+	// Очищаются и коллекции, созданные после снимка
+	current, err := s.collectionNames(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Restore(snapshot, current)
+	if errors.Is(err, repository.ErrTransactionsUnavailable) {
+		return nil, fmt.Errorf("%w: snapshot restore runs in transactions and requires MongoDB running as a replica set", ErrNotSupported)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
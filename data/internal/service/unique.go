@@ -0,0 +1,230 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/repository"
+	"github.com/go-mockingcode/models"
+)
+
+// Уникальность полей схемы гарантирует уникальный индекс репозитория по значениям
+// _unique. Проверка перед записью дает понятную ошибку по каждой операции пакета
+// и учитывает документы, записанные до того, как поле стало уникальным
+
+// uniqueFields возвращает имена полей схемы, помеченных Unique (id уникален и без этого)
+func uniqueFields(schema *models.Collection) []string {
+	if schema == nil {
+		return nil
+	}
+	var fields []string
+	for _, field := range schema.Fields {
+		if field.Unique && field.Name != "id" {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields
+}
+
+// duplicateError ошибка нарушения уникальности поля (ErrConflict)
+func duplicateError(field string) error {
+	return conflictError(fmt.Errorf("%w: field %q must be unique", repository.ErrDuplicateValue, field))
+}
+
+// isDocument проверяет, что documentID (data.id или ObjectID) указывает на doc
+func isDocument(doc *model.MockDocument, documentID string) bool {
+	if doc.ID.Hex() == documentID {
+		return true
+	}
+	id, ok := doc.Data["id"]
+	return ok && fmt.Sprint(id) == documentID
+}
+
+// checkUnique проверяет, что значения уникальных полей data не заняты другими
+// документами коллекции проекта. documentID - изменяемый документ ("" при создании)
+func (s *DocumentService) checkUnique(projectID int64, collectionName string, schema *models.Collection, data map[string]any, documentID string) error {
+	for _, field := range uniqueFields(schema) {
+		value, ok := repository.UniqueValue(data, field)
+		if !ok {
+			continue
+		}
+
+		docs, err := s.docRepo.FindByField(projectID, collectionName, field, []any{value})
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if documentID == "" || !isDocument(doc, documentID) {
+				return duplicateError(field)
+			}
+		}
+	}
+	return nil
+}
+
+// checkBulkUnique отклоняет операции create и update пакета, значения уникальных полей
+// которых заняты другими документами коллекции или более ранними операциями пакета.
+// Возвращает прошедшие проверку операции и результаты отклоненных
+func (s *DocumentService) checkBulkUnique(projectID int64, collectionName string, schema *models.Collection, ops []model.BulkOperation) ([]model.BulkOperation, []model.BulkItemResult, error) {
+	fields := uniqueFields(schema)
+	if len(fields) == 0 {
+		return ops, nil, nil
+	}
+
+	rejected := make(map[int]string) // Индекс операции в ops -> поле с занятым значением
+	for _, field := range fields {
+		var values []any
+		for _, op := range ops {
+			if op.Op == model.BulkDelete {
+				continue
+			}
+			if value, ok := repository.UniqueValue(op.Data, field); ok {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		// Все занятые значения поля читаются одним запросом на пакет
+		docs, err := s.docRepo.FindByField(projectID, collectionName, field, values)
+		if err != nil {
+			return nil, nil, err
+		}
+		markBulkDuplicates(field, ops, docs, rejected)
+	}
+
+	valid := ops[:0:0]
+	var results []model.BulkItemResult
+	for i, op := range ops {
+		field, isRejected := rejected[i]
+		if !isRejected {
+			valid = append(valid, op)
+			continue
+		}
+		results = append(results, model.BulkItemResult{
+			Index:  op.Index,
+			Op:     op.Op,
+			Status: http.StatusConflict,
+			ID:     op.ID,
+			Error:  duplicateError(field).Error(),
+		})
+	}
+	return valid, results, nil
+}
+
+// markBulkDuplicates отмечает в rejected операции пакета, значение поля field которых
+// занято документами коллекции docs или более ранними операциями пакета. Операции
+// выполняются по порядку: значения, освобожденные более ранними delete и update,
+// свободны для следующих операций
+func markBulkDuplicates(field string, ops []model.BulkOperation, docs []*model.MockDocument, rejected map[int]string) {
+	holders := newUniqueHolders()
+	for _, doc := range docs {
+		holders.add(field, doc)
+	}
+
+	for i, op := range ops {
+		if rejected[i] != "" {
+			continue
+		}
+		switch op.Op {
+		case model.BulkDelete:
+			holders.release(op.DocumentID)
+		case model.BulkUpdate:
+			value, ok := repository.UniqueValue(op.Data, field)
+			if ok && holders.takenByOther(repository.UniqueKey(field, value), op.DocumentID) {
+				rejected[i] = field
+				continue
+			}
+			// Прежнее значение документа освобождается
+			holders.release(op.DocumentID)
+			if ok {
+				holders.claim(repository.UniqueKey(field, value), holders.ref(op.DocumentID))
+			}
+		case model.BulkCreate:
+			value, ok := repository.UniqueValue(op.Data, field)
+			if !ok {
+				continue
+			}
+			key := repository.UniqueKey(field, value)
+			if holders.takenByOther(key, "") {
+				rejected[i] = field
+				continue
+			}
+			// Созданный документ доступен следующим операциям по явно заданному id
+			ref := fmt.Sprintf("op:%d", i)
+			if id, hasID := op.Data["id"]; hasID {
+				ref = fmt.Sprint(id)
+			}
+			holders.claim(key, ref)
+		}
+	}
+}
+
+// uniqueHolders документы, занимающие значения уникального поля по ходу пакета.
+// Документ обозначается ObjectID или, если создан пакетом, своим id
+type uniqueHolders struct {
+	byKey   map[string][]string // Значение (UniqueKey) -> документы
+	byRef   map[string][]string // Документ -> занятые им значения
+	aliases map[string]string   // data.id -> ObjectID документов коллекции
+}
+
+func newUniqueHolders() *uniqueHolders {
+	return &uniqueHolders{
+		byKey:   make(map[string][]string),
+		byRef:   make(map[string][]string),
+		aliases: make(map[string]string),
+	}
+}
+
+// add отмечает значение field существующего документа коллекции
+func (h *uniqueHolders) add(field string, doc *model.MockDocument) {
+	ref := doc.ID.Hex()
+	if id, ok := doc.Data["id"]; ok {
+		h.aliases[fmt.Sprint(id)] = ref
+	}
+	if value, ok := repository.UniqueValue(doc.Data, field); ok {
+		h.claim(repository.UniqueKey(field, value), ref)
+	}
+}
+
+// ref приводит ID документа из операции (data.id или ObjectID) к обозначению документа
+func (h *uniqueHolders) ref(documentID string) string {
+	if ref, ok := h.aliases[documentID]; ok {
+		return ref
+	}
+	return documentID
+}
+
+func (h *uniqueHolders) claim(key, ref string) {
+	h.byKey[key] = append(h.byKey[key], ref)
+	h.byRef[ref] = append(h.byRef[ref], key)
+}
+
+// release освобождает значения документа documentID
+func (h *uniqueHolders) release(documentID string) {
+	ref := h.ref(documentID)
+	for _, key := range h.byRef[ref] {
+		refs := h.byKey[key][:0]
+		for _, holder := range h.byKey[key] {
+			if holder != ref {
+				refs = append(refs, holder)
+			}
+		}
+		h.byKey[key] = refs
+	}
+	delete(h.byRef, ref)
+}
+
+// takenByOther проверяет, что значение занято документом, отличным от documentID
+// ("" - любым документом)
+func (h *uniqueHolders) takenByOther(key, documentID string) bool {
+	ref := h.ref(documentID)
+	for _, holder := range h.byKey[key] {
+		if documentID == "" || holder != ref {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func createOp(data map[string]any) model.BulkOperation {
+	return model.BulkOperation{Op: model.BulkCreate, Data: data}
+}
+
+func updateOp(documentID string, data map[string]any) model.BulkOperation {
+	return model.BulkOperation{Op: model.BulkUpdate, DocumentID: documentID, Data: data}
+}
+
+func deleteOp(documentID string) model.BulkOperation {
+	return model.BulkOperation{Op: model.BulkDelete, DocumentID: documentID}
+}
+
+func TestMarkBulkDuplicates(t *testing.T) {
+	alice := &model.MockDocument{ID: bson.NewObjectID(), Data: map[string]any{"id": int64(1), "email": "alice@example.com"}}
+	bob := &model.MockDocument{ID: bson.NewObjectID(), Data: map[string]any{"id": int64(2), "email": "bob@example.com"}}
+	docs := []*model.MockDocument{alice, bob}
+
+	tests := []struct {
+		name     string
+		ops      []model.BulkOperation
+		rejected map[int]string // Операции, отклоненные до проверки поля
+		want     map[int]string
+	}{
+		{
+			name: "distinct values",
+			ops: []model.BulkOperation{
+				createOp(map[string]any{"email": "carol@example.com"}),
+				createOp(map[string]any{"email": "dave@example.com"}),
+			},
+			want: map[int]string{},
+		},
+		{
+			name: "value of existing document",
+			ops: []model.BulkOperation{
+				createOp(map[string]any{"email": "alice@example.com"}),
+			},
+			want: map[int]string{0: "email"},
+		},
+		{
+			name: "same value twice in batch",
+			ops: []model.BulkOperation{
+				createOp(map[string]any{"email": "carol@example.com"}),
+				createOp(map[string]any{"email": "carol@example.com"}),
+				createOp(map[string]any{"email": "carol@example.com"}),
+			},
+			want: map[int]string{1: "email", 2: "email"},
+		},
+		{
+			name: "earlier delete frees value",
+			ops: []model.BulkOperation{
+				deleteOp("1"),
+				createOp(map[string]any{"email": "alice@example.com"}),
+			},
+			want: map[int]string{},
+		},
+		{
+			name: "delete by object id frees value",
+			ops: []model.BulkOperation{
+				deleteOp(alice.ID.Hex()),
+				createOp(map[string]any{"email": "alice@example.com"}),
+			},
+			want: map[int]string{},
+		},
+		{
+			name: "later delete does not free value",
+			ops: []model.BulkOperation{
+				createOp(map[string]any{"email": "alice@example.com"}),
+				deleteOp("1"),
+			},
+			want: map[int]string{0: "email"},
+		},
+		{
+			name: "update keeps own value",
+			ops: []model.BulkOperation{
+				updateOp("1", map[string]any{"email": "alice@example.com", "name": "Alice"}),
+			},
+			want: map[int]string{},
+		},
+		{
+			name: "update to value of other document",
+			ops: []model.BulkOperation{
+				updateOp("1", map[string]any{"email": "bob@example.com"}),
+			},
+			want: map[int]string{0: "email"},
+		},
+		{
+			name: "update frees previous value",
+			ops: []model.BulkOperation{
+				updateOp("2", map[string]any{"email": "robert@example.com"}),
+				updateOp("1", map[string]any{"email": "bob@example.com"}),
+				createOp(map[string]any{"email": "alice@example.com"}),
+			},
+			want: map[int]string{},
+		},
+		{
+			name: "update removing field frees value",
+			ops: []model.BulkOperation{
+				updateOp("1", map[string]any{"name": "Alice"}),
+				createOp(map[string]any{"email": "alice@example.com"}),
+			},
+			want: map[int]string{},
+		},
+		{
+			name: "document created in batch is addressed by id",
+			ops: []model.BulkOperation{
+				createOp(map[string]any{"id": int64(10), "email": "carol@example.com"}),
+				updateOp("10", map[string]any{"email": "caroline@example.com"}),
+				createOp(map[string]any{"email": "carol@example.com"}),
+				deleteOp("10"),
+				createOp(map[string]any{"email": "caroline@example.com"}),
+			},
+			want: map[int]string{},
+		},
+		{
+			name: "rejected operations do not claim values",
+			ops: []model.BulkOperation{
+				createOp(map[string]any{"email": "carol@example.com"}),
+				createOp(map[string]any{"email": "carol@example.com"}),
+			},
+			rejected: map[int]string{0: "name"},
+			want:     map[int]string{0: "name"},
+		},
+		{
+			name: "null and array values are not unique",
+			ops: []model.BulkOperation{
+				createOp(map[string]any{"email": nil}),
+				createOp(map[string]any{"email": nil}),
+				createOp(map[string]any{"email": []any{"alice@example.com"}}),
+			},
+			want: map[int]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected := make(map[int]string)
+			for i, field := range tt.rejected {
+				rejected[i] = field
+			}
+			markBulkDuplicates("email", tt.ops, docs, rejected)
+			if !reflect.DeepEqual(rejected, tt.want) {
+				t.Errorf("rejected = %v, want %v", rejected, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkBulkDuplicatesValueTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  any
+		second any
+		want   map[int]string
+	}{
+		{name: "string and number differ", first: "1", second: float64(1), want: map[int]string{}},
+		{name: "integer and float are equal", first: int64(1), second: float64(1), want: map[int]string{1: "code"}},
+		{name: "bool and string differ", first: true, second: "true", want: map[int]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := []model.BulkOperation{
+				createOp(map[string]any{"code": tt.first}),
+				createOp(map[string]any{"code": tt.second}),
+			}
+			rejected := make(map[int]string)
+			markBulkDuplicates("code", ops, nil, rejected)
+			if !reflect.DeepEqual(rejected, tt.want) {
+				t.Errorf("rejected = %v, want %v", rejected, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/models"
+)
+
+// Правила схемы, нарушения которых возвращаются клиенту
+const (
+	ruleRequired = "required"
+	ruleType     = "type"
+	ruleMin      = "min"
+	ruleMax      = "max"
+	ruleOptions  = "options"
+	ruleFormat   = "format"
+	ruleUnknown  = "unknown"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validateDocument проверяет данные документа по схеме коллекции и возвращает
+// *ValidationError со всеми нарушениями. Даты, переданные строкой, приводятся
+// к time.Time, чтобы храниться так же, как сгенерированные.
+// Коллекции без схемы (или без полей) не проверяются
+func validateDocument(schema *models.Collection, data map[string]any) error {
+	if schema == nil || len(schema.Fields) == 0 {
+		return nil
+	}
+
+	var violations []model.FieldViolation
+	known := map[string]bool{"id": true}
+
+	for _, field := range schema.Fields {
+		known[field.Name] = true
+		// id назначается сервисом
+		if field.Name == "id" {
+			continue
+		}
+
+		value, ok := data[field.Name]
+		if !ok || value == nil {
+			if field.Required {
+				violations = append(violations, violation(field.Name, ruleRequired, "is required"))
+			}
+			continue
+		}
+
+		converted, fieldViolations := validateField(field, value)
+		violations = append(violations, fieldViolations...)
+		if len(fieldViolations) == 0 {
+			data[field.Name] = converted
+		}
+	}
+
+	if schema.Config.Strict {
+		var unknown []string
+		for name := range data {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		slices.Sort(unknown)
+		for _, name := range unknown {
+			violations = append(violations, violation(name, ruleUnknown, "is not defined in collection schema"))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateField проверяет значение поля и возвращает его в виде для хранения
+func validateField(field models.FieldTemplate, value any) (any, []model.FieldViolation) {
+	switch field.Type {
+	case "number":
+		number, ok := numberValue(value)
+		if !ok {
+			return nil, []model.FieldViolation{violation(field.Name, ruleType, "must be a number")}
+		}
+		var violations []model.FieldViolation
+		if field.Min != nil && number < *field.Min {
+			violations = append(violations, violation(field.Name, ruleMin, "must be at least "+formatNumber(*field.Min)))
+		}
+		if field.Max != nil && number > *field.Max {
+			violations = append(violations, violation(field.Name, ruleMax, "must be at most "+formatNumber(*field.Max)))
+		}
+		return value, violations
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return nil, []model.FieldViolation{violation(field.Name, ruleType, "must be a boolean")}
+		}
+		return value, nil
+
+	case "date":
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			if t, err := parseDate(v); err == nil {
+				return t, nil
+			}
+		}
+		return nil, []model.FieldViolation{violation(field.Name, ruleType, "must be a date (RFC 3339 or YYYY-MM-DD)")}
+
+	case "string", "":
+		s, ok := value.(string)
+		if !ok {
+			return nil, []model.FieldViolation{violation(field.Name, ruleType, "must be a string")}
+		}
+		var violations []model.FieldViolation
+		if len(field.Options) > 0 && !slices.Contains(field.Options, s) {
+			violations = append(violations, violation(field.Name, ruleOptions, fmt.Sprintf("must be one of %v", field.Options)))
+		}
+		if message := checkFormat(field.Format, s); message != "" {
+			violations = append(violations, violation(field.Name, ruleFormat, message))
+		}
+		return value, violations
+
+	case "reference":
+		if field.Cardinality != models.CardinalityMany {
+			if !isReferenceID(value) {
+				return nil, []model.FieldViolation{violation(field.Name, ruleType, "must be a document id")}
+			}
+			return value, nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return nil, []model.FieldViolation{violation(field.Name, ruleType, "must be an array of document ids")}
+		}
+		for _, item := range items {
+			if !isReferenceID(item) {
+				return nil, []model.FieldViolation{violation(field.Name, ruleType, "must be an array of document ids")}
+			}
+		}
+		return value, nil
+
+	default:
+		// Неизвестные типы не проверяются
+		return value, nil
+	}
+}
+
+// isReferenceID проверяет, что значение может быть id документа (число или непустая строка)
+func isReferenceID(value any) bool {
+	if s, ok := value.(string); ok {
+		return s != ""
+	}
+	_, ok := numberValue(value)
+	return ok
+}
+
+// checkFormat проверяет строку по формату поля. Форматы без строгого вида
+// (name, address, city...) влияют только на генерацию и не проверяются
+func checkFormat(format, value string) string {
+	switch format {
+	case "email":
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			return "must be a valid email"
+		}
+	case "url":
+		if u, err := url.ParseRequestURI(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid absolute URL"
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(value) {
+			return "must be a valid UUID"
+		}
+	}
+	return ""
+}
+
+// numberValue возвращает числовое значение (из JSON или прочитанное из MongoDB)
+func numberValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func violation(field, rule, message string) model.FieldViolation {
+	return model.FieldViolation{Field: field, Rule: rule, Message: message}
+}
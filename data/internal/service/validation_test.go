@@ -0,0 +1,191 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-mockingcode/models"
+)
+
+func float64Ptr(v float64) *float64 { return &v }
+
+// validationSchema схема коллекции тестов валидации
+func validationSchema(strict bool) *models.Collection {
+	return &models.Collection{
+		Name: "users",
+		Fields: []models.FieldTemplate{
+			{Name: "id", Type: "number"},
+			{Name: "name", Type: "string", Required: true},
+			{Name: "email", Type: "string", Format: "email"},
+			{Name: "site", Type: "string", Format: "url"},
+			{Name: "token", Type: "string", Format: "uuid"},
+			{Name: "status", Type: "string", Options: []string{"active", "blocked"}},
+			{Name: "age", Type: "number", Min: float64Ptr(0), Max: float64Ptr(150)},
+			{Name: "verified", Type: "boolean"},
+			{Name: "birthday", Type: "date"},
+			{Name: "teamId", Type: "reference", Reference: "teams"},
+			{Name: "tagIds", Type: "reference", Reference: "tags", Cardinality: models.CardinalityMany},
+			{Name: "extra", Type: "custom"},
+		},
+		Config: models.CollectionConfig{Strict: strict},
+	}
+}
+
+func TestValidateDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *models.Collection
+		data   map[string]any
+		want   []string // "field:rule" в порядке нарушений
+	}{
+		{
+			name:   "no schema",
+			schema: nil,
+			data:   map[string]any{"anything": 1},
+		},
+		{
+			name:   "schema without fields",
+			schema: &models.Collection{Config: models.CollectionConfig{Strict: true}},
+			data:   map[string]any{"anything": 1},
+		},
+		{
+			name:   "valid document",
+			schema: validationSchema(true),
+			data: map[string]any{
+				"id":       "not checked",
+				"name":     "Alice",
+				"email":    "alice@example.com",
+				"site":     "https://example.com/alice",
+				"token":    "123e4567-e89b-12d3-a456-426614174000",
+				"status":   "active",
+				"age":      float64(30),
+				"verified": true,
+				"birthday": "1990-05-01",
+				"teamId":   float64(2),
+				"tagIds":   []any{"a", float64(1)},
+				"extra":    []any{"anything"},
+			},
+		},
+		{
+			name:   "required field missing or null",
+			schema: validationSchema(false),
+			data:   map[string]any{"name": nil},
+			want:   []string{"name:required"},
+		},
+		{
+			name:   "optional fields may be null",
+			schema: validationSchema(false),
+			data:   map[string]any{"name": "Alice", "email": nil, "age": nil},
+		},
+		{
+			name:   "wrong types",
+			schema: validationSchema(false),
+			data: map[string]any{
+				"name":     float64(1),
+				"age":      "30",
+				"verified": "yes",
+				"birthday": "01.05.1990",
+				"teamId":   "",
+				"tagIds":   "a",
+			},
+			want: []string{"name:type", "age:type", "verified:type", "birthday:type", "teamId:type", "tagIds:type"},
+		},
+		{
+			name:   "reference array with invalid item",
+			schema: validationSchema(false),
+			data:   map[string]any{"name": "Alice", "tagIds": []any{"a", true}},
+			want:   []string{"tagIds:type"},
+		},
+		{
+			name:   "number range",
+			schema: validationSchema(false),
+			data:   map[string]any{"name": "Alice", "age": float64(-1)},
+			want:   []string{"age:min"},
+		},
+		{
+			name:   "number above max",
+			schema: validationSchema(false),
+			data:   map[string]any{"name": "Alice", "age": int64(151)},
+			want:   []string{"age:max"},
+		},
+		{
+			name:   "options and formats",
+			schema: validationSchema(false),
+			data: map[string]any{
+				"name":   "Alice",
+				"email":  "Alice <alice@example.com>",
+				"site":   "/relative",
+				"token":  "123",
+				"status": "deleted",
+			},
+			want: []string{"email:format", "site:format", "token:format", "status:options"},
+		},
+		{
+			name:   "unknown fields allowed without strict mode",
+			schema: validationSchema(false),
+			data:   map[string]any{"name": "Alice", "nickname": "al"},
+		},
+		{
+			name:   "strict mode rejects unknown fields in name order",
+			schema: validationSchema(true),
+			data:   map[string]any{"name": "Alice", "nickname": "al", "avatar": "a.png"},
+			want:   []string{"avatar:unknown", "nickname:unknown"},
+		},
+		{
+			name:   "strict mode allows id without schema field",
+			schema: &models.Collection{Fields: []models.FieldTemplate{{Name: "name"}}, Config: models.CollectionConfig{Strict: true}},
+			data:   map[string]any{"id": float64(1), "name": "Alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDocument(tt.schema, tt.data)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("validateDocument() error = %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("validateDocument() error = %v, want *ValidationError", err)
+			}
+			var got []string
+			for _, v := range validationErr.Violations {
+				got = append(got, v.Field+":"+v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDocumentConvertsDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  time.Time
+	}{
+		{name: "date only", value: "1990-05-01", want: time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "RFC 3339", value: "1990-05-01T10:20:30Z", want: time.Date(1990, 5, 1, 10, 20, 30, 0, time.UTC)},
+		{name: "time value", value: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC), want: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]any{"name": "Alice", "birthday": tt.value}
+			if err := validateDocument(validationSchema(false), data); err != nil {
+				t.Fatalf("validateDocument() error = %v", err)
+			}
+			got, ok := data["birthday"].(time.Time)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("birthday = %#v, want %v", data["birthday"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-mockingcode/data/internal/model"
+	"github.com/go-mockingcode/data/internal/repository"
+	"github.com/go-mockingcode/models"
+)
+
+// Заголовки запроса вебхука
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// maxWebhookResponse сколько байт ответа получателя сохраняется в журнале
+const maxWebhookResponse = 1024
+
+// maxWebhookRequests одновременных запросов к получателям (обработчиков очереди доставок)
+const maxWebhookRequests = 16
+
+// webhookQueueSize размер очередей операций, ожидающих рассылки, и доставок.
+// Операции сверх очереди пропускаются с записью в лог, доставки ждут места в очереди
+const webhookQueueSize = 1024
+
+// WebhookSource возвращает включенные вебхуки проекта (project service)
+type WebhookSource interface {
+	GetWebhooks(projectID int64) ([]models.Webhook, error)
+}
+
+type WebhookService struct {
+	source      WebhookSource
+	repo        *repository.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	logTTL      time.Duration
+	changes     chan model.ChangeSet // Операции, ожидающие рассылки
+	jobs        chan *webhookJob     // Доставки, ожидающие попытки
+}
+
+// webhookJob доставка события одному вебхуку
+type webhookJob struct {
+	delivery *model.WebhookDelivery
+	secret   string
+	attempts int
+	backoff  time.Duration // Пауза перед следующей попыткой
+}
+
+// NewWebhookService создает сервис вебхуков и запускает обработчики очередей.
+// allowLocal разрешает получателей на loopback и в частных сетях (локальный приемник при разработке)
+func NewWebhookService(source WebhookSource, repo *repository.WebhookRepository, maxAttempts int, backoff, timeout, logTTL time.Duration, allowLocal bool) *WebhookService {
+	s := &WebhookService{
+		source: source,
+		repo:   repo,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: newWebhookTransport(allowLocal),
+			// Редирект считается ответом получателя: POST не превращается в GET
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxAttempts: max(maxAttempts, 1),
+		backoff:     backoff,
+		logTTL:      logTTL,
+		changes:     make(chan model.ChangeSet, webhookQueueSize),
+		jobs:        make(chan *webhookJob, webhookQueueSize),
+	}
+
+	go func() {
+		for set := range s.changes {
+			s.dispatch(set)
+		}
+	}()
+	for range maxWebhookRequests {
+		go func() {
+			for job := range s.jobs {
+				s.deliver(job)
+			}
+		}()
+	}
+	return s
+}
+
+// HandleChanges слушатель изменений DocumentRepository: операция ставится в очередь
+// рассылки и не задерживает ответ на запрос, изменивший документы. Если очередь
+// заполнена, события операции не отправляются
+func (s *WebhookService) HandleChanges(set model.ChangeSet) {
+	select {
+	case s.changes <- set:
+	default:
+		slog.Warn("Webhook queue is full, dropping change events",
+			slog.Int64("project_id", set.ProjectID),
+			slog.String("collection", set.CollectionName),
+			slog.Int("changes", len(set.Changes)))
+	}
+}
+
+// dispatch записывает в журнал доставки событий операции подписанным на них вебхукам
+// проекта и ставит их в очередь. Пока очередь доставок заполнена, dispatch ждет
+func (s *WebhookService) dispatch(set model.ChangeSet) {
+	webhooks, err := s.source.GetWebhooks(set.ProjectID)
+	if err != nil {
+		slog.Warn("Failed to get project webhooks",
+			slog.Int64("project_id", set.ProjectID),
+			slog.String("error", err.Error()))
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	var jobs []*webhookJob
+	var deliveries []*model.WebhookDelivery
+	for _, payload := range ChangeEvents(set) {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			slog.Warn("Failed to encode webhook payload",
+				slog.String("event", payload.Event),
+				slog.String("error", err.Error()))
+			continue
+		}
+		for i := range webhooks {
+			if webhooks[i].Matches(set.CollectionName, payload.Event) {
+				delivery := s.newDelivery(&webhooks[i], &payload, body)
+				deliveries = append(deliveries, delivery)
+				jobs = append(jobs, &webhookJob{delivery: delivery, secret: webhooks[i].Secret, backoff: s.backoff})
+			}
+		}
+	}
+
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		// События доставляются и без записи в журнале
+		slog.Warn("Failed to log webhook deliveries",
+			slog.Int64("project_id", set.ProjectID),
+			slog.String("error", err.Error()))
+	}
+	for _, job := range jobs {
+		s.jobs <- job
+	}
+}
+
+// newDelivery формирует запись журнала доставки события вебхуку
+func (s *WebhookService) newDelivery(webhook *models.Webhook, payload *model.ChangeEvent, body []byte) *model.WebhookDelivery {
+	now := time.Now()
+	return &model.WebhookDelivery{
+		ProjectID:  webhook.ProjectID,
+		WebhookID:  webhook.ID,
+		URL:        webhook.URL,
+		EventID:    payload.ID,
+		Event:      payload.Event,
+		Collection: payload.Collection,
+		Payload:    body,
+		Status:     model.DeliveryPending,
+		Attempts:   []model.WebhookAttempt{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		ExpiresAt:  now.Add(s.logTTL),
+	}
+}
+
+// deliver выполняет попытку доставки. Пока получатель не ответит 2xx и попытки не
+// закончатся, доставка возвращается в очередь после экспоненциальной паузы.
+// Повторные попытки выполняются в памяти и не переживают перезапуск сервиса
+func (s *WebhookService) deliver(job *webhookJob) {
+	delivery := job.delivery
+	job.attempts++
+	result, err := s.attempt(delivery, job.secret)
+
+	delivery.NextAttemptAt = nil
+	switch {
+	case err == nil && result.StatusCode >= 200 && result.StatusCode < 300:
+		delivery.Status = model.DeliverySucceeded
+	case job.attempts >= s.maxAttempts, errors.Is(err, errWebhookTarget):
+		// Запрещенный адрес получателя не станет доступен при повторе
+		delivery.Status = model.DeliveryFailed
+	default:
+		next := time.Now().Add(job.backoff)
+		delivery.NextAttemptAt = &next
+	}
+	s.logAttempt(delivery, result)
+
+	switch delivery.Status {
+	case model.DeliveryPending:
+		s.retry(job)
+	case model.DeliveryFailed:
+		slog.Warn("Webhook delivery failed",
+			slog.Int64("webhook_id", delivery.WebhookID),
+			slog.String("event", delivery.Event),
+			slog.Int("attempts", job.attempts))
+	}
+}
+
+// retry возвращает доставку в очередь после паузы. Обработчики очереди не ждут
+// паузу, а доставка, для которой в очереди нет места, считается неудачной
+func (s *WebhookService) retry(job *webhookJob) {
+	delay := job.backoff
+	job.backoff *= 2
+	time.AfterFunc(delay, func() {
+		select {
+		case s.jobs <- job:
+		default:
+			delivery := job.delivery
+			delivery.Status = model.DeliveryFailed
+			delivery.NextAttemptAt = nil
+			s.logAttempt(delivery, model.WebhookAttempt{At: time.Now(), Error: "webhook delivery queue is full"})
+			slog.Warn("Webhook queue is full, delivery failed",
+				slog.Int64("webhook_id", delivery.WebhookID),
+				slog.String("event", delivery.Event))
+		}
+	})
+}
+
+// logAttempt записывает попытку и статус доставки в журнал
+func (s *WebhookService) logAttempt(delivery *model.WebhookDelivery, result model.WebhookAttempt) {
+	if delivery.ID.IsZero() {
+		return
+	}
+	if err := s.repo.AddAttempt(delivery, result); err != nil {
+		slog.Warn("Failed to log webhook attempt",
+			slog.String("delivery_id", delivery.ID.Hex()),
+			slog.String("error", err.Error()))
+	}
+}
+
+// attempt выполняет одну попытку доставки. Ошибка запроса возвращается вместе
+// с результатом попытки, в котором она записана для журнала
+func (s *WebhookService) attempt(delivery *model.WebhookDelivery, secret string) (model.WebhookAttempt, error) {
+	start := time.Now()
+	result := model.WebhookAttempt{At: start}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MockingCode-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhook(secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.Error = err.Error()
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	// Редиректы не выполняются, а тело ответа 3xx в журнал не попадает
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		response, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+		result.Response = string(response)
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// signWebhook подпись тела вебхука: hex HMAC-SHA256 секрета от "<timestamp>.<body>"
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var deliveryStatuses = []string{model.DeliveryPending, model.DeliverySucceeded, model.DeliveryFailed}
+
+// ListDeliveries возвращает журнал доставок вебхуков проекта, новые первыми.
+// webhookID 0 и пустой status не ограничивают выборку
+func (s *WebhookService) ListDeliveries(projectID, webhookID int64, status string, opts model.QueryOptions) ([]model.WebhookDelivery, error) {
+	if status != "" && !slices.Contains(deliveryStatuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of pending, succeeded, failed", ErrInvalidQuery)
+	}
+	return s.repo.ListDeliveries(projectID, webhookID, status, opts)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+)
+
+// errWebhookTarget адрес получателя вебхука во внутренней сети
+var errWebhookTarget = errors.New("webhook target address is not allowed")
+
+// reservedPrefixes служебные диапазоны, не покрытые методами netip.Addr
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// checkWebhookAddr отклоняет адреса, через которые вебхук мог бы читать внутреннюю сеть:
+// link-local (в том числе метаданные облака), multicast и служебные диапазоны всегда,
+// loopback и частные сети - если не разрешен локальный приемник (allowLocal)
+func checkWebhookAddr(addr netip.Addr, allowLocal bool) error {
+	addr = addr.Unmap()
+	switch {
+	case addr.IsUnspecified(), addr.IsLinkLocalUnicast(), addr.IsMulticast(),
+		addr.IsLinkLocalMulticast(), addr.IsInterfaceLocalMulticast():
+		return fmt.Errorf("%w: %s", errWebhookTarget, addr)
+	case addr.IsLoopback(), addr.IsPrivate():
+		if !allowLocal {
+			return fmt.Errorf("%w: %s is a local address", errWebhookTarget, addr)
+		}
+		return nil
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: %s", errWebhookTarget, addr)
+		}
+	}
+	return nil
+}
+
+// newWebhookTransport транспорт запросов к получателям. Адрес проверяется при
+// подключении, после разрешения имени, поэтому смена DNS-записи после сохранения
+// вебхука его не обходит. Прокси из окружения не используется: он подключался бы
+// к получателю сам, минуя проверку
+func newWebhookTransport(allowLocal bool) *http.Transport {
+	dialer := &net.Dialer{
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", errWebhookTarget, address)
+			}
+			return checkWebhookAddr(addrPort.Addr(), allowLocal)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
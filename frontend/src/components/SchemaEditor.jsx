@@ -8,6 +8,7 @@ const FAKER_TYPES = [
     { value: 'number', label: 'Number (число)' },
     { value: 'boolean', label: 'Boolean (true/false)' },
     { value: 'date', label: 'Date (дата)' },
+    { value: 'reference', label: 'Reference (ссылка)' },
 ];
 
 const FAKER_FORMATS = {
@@ -26,8 +27,14 @@ const FAKER_FORMATS = {
     number: [],
     boolean: [],
     date: [],
+    reference: [],
 };
 
+const CARDINALITIES = [
+    { value: 'one', label: 'Один' },
+    { value: 'many', label: 'Много' },
+];
+
 export function SchemaEditor({ collection, apiKey, onSave, onCancel }) {
     // Инициализируем поля, убеждаясь что id всегда readOnly
     const initFields = () => {
@@ -107,25 +114,16 @@ export function SchemaEditor({ collection, apiKey, onSave, onCancel }) {
             setIsSaving(true);
             setError('');
             
-            // Генерируем данные
-            const response = await apiClient.generateDocuments(
-                fields, 
+            // Генерируем и сохраняем данные по схеме коллекции
+            // (ссылки заполняются id существующих документов)
+            const response = await apiClient.generateCollectionDocuments(
+                apiKey,
+                collection.name,
                 generateCount
             );
             
-            // Сохраняем каждый документ через обычный API
-            let savedCount = 0;
-            for (const doc of response.documents) {
-                try {
-                    await apiClient.createDocument(apiKey, collection.name, doc);
-                    savedCount++;
-                } catch (err) {
-                    console.error('Failed to save document:', err);
-                }
-            }
-            
             // Показываем результат
-            alert(`Сгенерировано ${response.count} документов, сохранено ${savedCount}!`);
+            alert(`Сгенерировано и сохранено ${response.count} документов!`);
             
             // Вызываем callback для обновления данных в родительском компоненте
             if (onSave) {
@@ -241,6 +239,31 @@ export function SchemaEditor({ collection, apiKey, onSave, onCancel }) {
                                                 ))}
                                             </select>
                                         )}
+                                        {field.type === 'reference' && (
+                                            <div className="flex gap-2">
+                                                <input
+                                                    type="text"
+                                                    value={field.reference || ''}
+                                                    onInput={(e) => updateField(index, 'reference', e.target.value)}
+                                                    className="input text-sm"
+                                                    placeholder="users"
+                                                    disabled={!isEditMode || field.readOnly || isSaving}
+                                                    required
+                                                />
+                                                <select
+                                                    value={field.cardinality || 'one'}
+                                                    onChange={(e) => updateField(index, 'cardinality', e.target.value)}
+                                                    className="input text-sm"
+                                                    disabled={!isEditMode || field.readOnly || isSaving}
+                                                >
+                                                    {CARDINALITIES.map(c => (
+                                                        <option key={c.value} value={c.value}>
+                                                            {c.label}
+                                                        </option>
+                                                    ))}
+                                                </select>
+                                            </div>
+                                        )}
                                         {field.type === 'number' && (
                                             <input
                                                 type="text"
@@ -1,5 +1,7 @@
 const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080';
 
+const UI_CHANGE_SOURCE = { 'X-Change-Source': 'ui' };
+
 class APIClient {
     constructor() {
         this.baseURL = API_BASE_URL;
@@ -152,7 +154,74 @@ class APIClient {
         });
     }
 
-    // Data endpoints (public API с api_key)
+    // OpenAPI 3.1 документ публичного API проекта (для Swagger UI и генераторов клиентов)
+    async getProjectOpenAPI(projectId) {
+        return this.request(`/projects/${projectId}/openapi.json`);
+    }
+
+    // Custom endpoints (пользовательские маршруты с шаблонным ответом)
+    async getEndpoints(projectId) {
+        const response = await this.request(`/projects/${projectId}/endpoints`);
+        // Backend возвращает { endpoints: [...], count: N }
+        return response.endpoints || [];
+    }
+
+    async createEndpoint(projectId, data) {
+        return this.request(`/projects/${projectId}/endpoints`, {
+            method: 'POST',
+            body: JSON.stringify(data),
+        });
+    }
+
+    async updateEndpoint(projectId, endpointId, data) {
+        return this.request(`/projects/${projectId}/endpoints/${endpointId}`, {
+            method: 'PUT',
+            body: JSON.stringify(data),
+        });
+    }
+
+    async deleteEndpoint(projectId, endpointId) {
+        return this.request(`/projects/${projectId}/endpoints/${endpointId}`, {
+            method: 'DELETE',
+        });
+    }
+
+    // Webhooks (уведомления об изменениях документов)
+    async getWebhooks(projectId) {
+        const response = await this.request(`/projects/${projectId}/webhooks`);
+        // Backend возвращает { webhooks: [...], count: N }
+        return response.webhooks || [];
+    }
+
+    async createWebhook(projectId, data) {
+        return this.request(`/projects/${projectId}/webhooks`, {
+            method: 'POST',
+            body: JSON.stringify(data),
+        });
+    }
+
+    async updateWebhook(projectId, webhookId, data) {
+        return this.request(`/projects/${projectId}/webhooks/${webhookId}`, {
+            method: 'PUT',
+            body: JSON.stringify(data),
+        });
+    }
+
+    async deleteWebhook(projectId, webhookId) {
+        return this.request(`/projects/${projectId}/webhooks/${webhookId}`, {
+            method: 'DELETE',
+        });
+    }
+
+    async getWebhookDeliveries(apiKey, params = {}) {
+        const query = new URLSearchParams(params).toString();
+        const response = await this.request(`/${apiKey}/_webhooks/deliveries${query ? `?${query}` : ''}`);
+        // Backend возвращает { deliveries: [...], count: N }
+        return response.deliveries || [];
+    }
+
+    // Data endpoints (public API с api_key).
+    // Изменения из интерфейса помечаются в истории документов источником ui
     async getCollectionData(apiKey, collectionName) {
         const response = await this.request(`/${apiKey}/${collectionName}`);
         // Backend возвращает чистый массив документов
@@ -162,6 +231,7 @@ class APIClient {
     async createDocument(apiKey, collectionName, data) {
         return this.request(`/${apiKey}/${collectionName}`, {
             method: 'POST',
+            headers: UI_CHANGE_SOURCE,
             body: JSON.stringify(data),
         });
     }
@@ -169,6 +239,7 @@ class APIClient {
     async updateDocument(apiKey, collectionName, documentId, data) {
         return this.request(`/${apiKey}/${collectionName}/${documentId}`, {
             method: 'PUT',
+            headers: UI_CHANGE_SOURCE,
             body: JSON.stringify(data),
         });
     }
@@ -176,15 +247,45 @@ class APIClient {
     async deleteDocument(apiKey, collectionName, documentId) {
         return this.request(`/${apiKey}/${collectionName}/${documentId}`, {
             method: 'DELETE',
+            headers: UI_CHANGE_SOURCE,
         });
     }
 
     async flushCollection(apiKey, collectionName) {
         return this.request(`/${apiKey}/${collectionName}`, {
             method: 'DELETE',
+            headers: UI_CHANGE_SOURCE,
+        });
+    }
+
+    async generateCollectionDocuments(apiKey, collectionName, count = 10, seed = null) {
+        const body = { count };
+
+        if (seed !== null) {
+            body.seed = seed;
+        }
+
+        return this.request(`/${apiKey}/${collectionName}/_generate`, {
+            method: 'POST',
+            body: JSON.stringify(body),
         });
     }
 
+    // GraphQL API проекта: схема строится по коллекциям со схемой полей
+    async graphql(apiKey, query, variables = {}) {
+        return this.request(`/${apiKey}/graphql`, {
+            method: 'POST',
+            headers: UI_CHANGE_SOURCE,
+            body: JSON.stringify({ query, variables }),
+        });
+    }
+
+    // Поток изменений коллекции (SSE). params: { _events: 'document.created,...', field: value }
+    streamCollection(apiKey, collectionName, params = {}) {
+        const query = new URLSearchParams(params).toString();
+        return new EventSource(`${this.baseURL}/${apiKey}/${collectionName}/_stream${query ? `?${query}` : ''}`);
+    }
+
     async generateDocuments(fields, count = 10, seed = null) {
         const body = {
             fields: fields,
@@ -24,6 +24,9 @@ const DEFAULT_VALUES = {
     },
     date: {
         '': '2024-01-15T10:30:00Z'
+    },
+    reference: {
+        '': 1
     }
 };
 
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -22,7 +24,8 @@ func (c *DataClient) ProxyRequest(r *http.Request, path string) (*http.Response,
 	// Create new request to data service
 	url := c.baseURL + path
 
-	proxyReq, err := http.NewRequest(r.Method, url, r.Body)
+	// The client's context cancels long-lived upstream requests (streams) when the client goes away
+	proxyReq, err := http.NewRequestWithContext(r.Context(), r.Method, url, r.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy request: %w", err)
 	}
@@ -43,3 +46,47 @@ func (c *DataClient) ProxyRequest(r *http.Request, path string) (*http.Response,
 	return resp, nil
 }
 
+// CopyUpgrade completes a protocol switch (WebSocket) proxied through ProxyRequest:
+// sends the upstream 101 response to the client and pipes both connections until one side closes
+func CopyUpgrade(w http.ResponseWriter, resp *http.Response) error {
+	upstream, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return fmt.Errorf("upstream response body is not writable")
+	}
+	defer upstream.Close()
+
+	conn, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return fmt.Errorf("failed to hijack client connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := writeSwitchingProtocols(buf.Writer, resp); err != nil {
+		return err
+	}
+
+	errc := make(chan error, 2)
+	go func() {
+		// buf.Reader holds client data read together with the request
+		_, err := io.Copy(upstream, buf.Reader)
+		errc <- err
+	}()
+	go func() {
+		_, err := io.Copy(conn, upstream)
+		errc <- err
+	}()
+	return <-errc
+}
+
+func writeSwitchingProtocols(w *bufio.Writer, resp *http.Response) error {
+	if _, err := fmt.Fprintf(w, "HTTP/1.1 %s\r\n", resp.Status); err != nil {
+		return err
+	}
+	if err := resp.Header.Write(w); err != nil {
+		return err
+	}
+	if _, err := w.WriteString("\r\n"); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -22,7 +22,7 @@ func NewProjectClient(baseURL string) *ProjectClient {
 func (c *ProjectClient) ProxyRequest(r *http.Request, path string) (*http.Response, error) {
 	// Create new request to project service
 	url := c.baseURL + path
-	
+
 	proxyReq, err := http.NewRequest(r.Method, url, r.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy request: %w", err)
@@ -57,7 +57,33 @@ func CopyResponse(w http.ResponseWriter, resp *http.Response) error {
 	w.WriteHeader(resp.StatusCode)
 
 	// Copy body
+	if resp.ContentLength < 0 {
+		// Unknown length means a streamed body (SSE, NDJSON export): pass every chunk on as it arrives
+		return copyFlushing(w, resp.Body)
+	}
 	_, err := io.Copy(w, resp.Body)
 	return err
 }
 
+// copyFlushing copies the body and flushes the client connection after every read
+func copyFlushing(w http.ResponseWriter, body io.Reader) error {
+	controller := http.NewResponseController(w)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			if ferr := controller.Flush(); ferr != nil {
+				return ferr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -22,6 +22,7 @@ type Config struct {
 	CORSAllowedOrigins []string
 	CORSAllowedMethods []string
 	CORSAllowedHeaders []string
+	CORSExposedHeaders []string
 
 	// Rate Limiting
 	RateLimitEnabled bool
@@ -60,11 +61,11 @@ func Load() *Config {
 		ProjectGRPCURL: projectGRPCURL,
 
 		CORSAllowedOrigins: []string{"*"}, // TODO: configure properly
-		CORSAllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		CORSAllowedHeaders: []string{"Authorization", "Content-Type"},
+		CORSAllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		CORSAllowedHeaders: []string{"Authorization", "Content-Type", "X-Mock-Fail", "If-Match", "If-None-Match", "If-Modified-Since", "Idempotency-Key", "X-Change-Source"},
+		CORSExposedHeaders: []string{"X-Total-Count", "Link", "X-Next-Cursor", "ETag", "Last-Modified", "Idempotent-Replayed", "Content-Disposition"},
 
 		RateLimitEnabled: env.GetBool("RATE_LIMIT_ENABLED", false),
 		RateLimitPerMin:  env.GetInt("RATE_LIMIT_PER_MIN", 100),
 	}
 }
-
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -23,7 +25,7 @@ func (h *PublicAPIHandler) HandlePublicAPI(w http.ResponseWriter, r *http.Reques
 	// Extract path: /{api_key}/{collection}[/{id}]
 	// Remove /{api_key} prefix to get /{collection}[/{id}]
 	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
-	
+
 	if len(pathParts) < 2 {
 		writeError(w, http.StatusBadRequest, "Invalid path")
 		return
@@ -32,20 +34,27 @@ func (h *PublicAPIHandler) HandlePublicAPI(w http.ResponseWriter, r *http.Reques
 	// pathParts: ["{api_key}", "collection", ...optional id]
 	// We need: /{collection}[/{id}] for Data Service
 	dataPath := "/" + strings.Join(pathParts[1:], "/")
-	
+
 	slog.Debug("proxying to data service (public API)",
 		slog.String("original_path", r.URL.Path),
 		slog.String("data_path", dataPath),
 	)
-	
+
 	// Add query parameters
 	if r.URL.RawQuery != "" {
 		dataPath += "?" + r.URL.RawQuery
 	}
 
+	// Public URL info for links built by Data Service (pagination Link header)
+	setForwardedHeaders(r, "/"+pathParts[0])
+
 	// Proxy to Data Service
 	resp, err := h.dataClient.ProxyRequest(r, dataPath)
 	if err != nil {
+		// Data Service closed the connection without a response (simulated drop): drop the client connection too
+		if errors.Is(err, io.EOF) {
+			panic(http.ErrAbortHandler)
+		}
 		slog.Error("failed to proxy to data service", slog.String("error", err.Error()))
 		writeError(w, http.StatusBadGateway, "Failed to reach data service")
 		return
@@ -54,8 +63,34 @@ func (h *PublicAPIHandler) HandlePublicAPI(w http.ResponseWriter, r *http.Reques
 
 	slog.Debug("received response from data service", slog.Int("status", resp.StatusCode))
 
+	// WebSocket upgrade accepted by Data Service (/{collection}/_stream)
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		if err := client.CopyUpgrade(w, resp); err != nil {
+			slog.Debug("upgraded connection closed", slog.String("error", err.Error()))
+		}
+		return
+	}
+
 	if err := client.CopyResponse(w, resp); err != nil {
 		slog.Error("failed to copy response", slog.String("error", err.Error()))
+		// Abort instead of finishing the response so the client sees a truncated body
+		panic(http.ErrAbortHandler)
 	}
 }
 
+// setForwardedHeaders passes the public host, scheme and path prefix to the upstream service
+func setForwardedHeaders(r *http.Request, prefix string) {
+	proto := r.Header.Get("X-Forwarded-Proto")
+	if proto == "" {
+		proto = "http"
+		if r.TLS != nil {
+			proto = "https"
+		}
+	}
+
+	if r.Header.Get("X-Forwarded-Host") == "" {
+		r.Header.Set("X-Forwarded-Host", r.Host)
+	}
+	r.Header.Set("X-Forwarded-Proto", proto)
+	r.Header.Set("X-Forwarded-Prefix", prefix)
+}
@@ -16,7 +16,7 @@ func CORSMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
 			)
-			
+
 			// Set CORS headers
 			origin := r.Header.Get("Origin")
 			if origin != "" && isOriginAllowed(origin, cfg.CORSAllowedOrigins) {
@@ -27,6 +27,9 @@ func CORSMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 
 			w.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.CORSAllowedMethods, ", "))
 			w.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.CORSAllowedHeaders, ", "))
+			if len(cfg.CORSExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(cfg.CORSExposedHeaders, ", "))
+			}
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 			w.Header().Set("Access-Control-Max-Age", "3600")
 
@@ -49,4 +52,3 @@ func isOriginAllowed(origin string, allowedOrigins []string) bool {
 	}
 	return false
 }
-
@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Collection represents a data collection in project
 type Collection struct {
@@ -17,18 +23,204 @@ type Collection struct {
 
 // FieldTemplate represents field for generation
 type FieldTemplate struct {
-	Name     string   `json:"name" example:"email"`
-	Type     string   `json:"type" example:"string" enums:"string,number,boolean,date"`
-	Format   string   `json:"format,omitempty" example:"email"`
-	Required bool     `json:"required" example:"true"`
-	Unique   bool     `json:"unique" example:"false"`
-	Min      *float64 `json:"min,omitempty" example:"0"`                   // для numbers
-	Max      *float64 `json:"max,omitempty" example:"100"`                 // для numbers
-	Options  []string `json:"options,omitempty" example:"active,inactive"` // для enum
+	Name       string   `json:"name" example:"email"`
+	Type       string   `json:"type" example:"string" enums:"string,number,boolean,date,reference"`
+	Format     string   `json:"format,omitempty" example:"email"`
+	Required   bool     `json:"required" example:"true"`
+	Unique     bool     `json:"unique" example:"false"`
+	Searchable bool     `json:"searchable,omitempty" example:"true"`         // участвует в поиске ?q=
+	Min        *float64 `json:"min,omitempty" example:"0"`                   // для numbers
+	Max        *float64 `json:"max,omitempty" example:"100"`                 // для numbers
+	Options    []string `json:"options,omitempty" example:"active,inactive"` // для enum
+
+	Reference   string `json:"reference,omitempty" example:"users"`                  // для reference: коллекция, на data.id которой ссылается поле
+	Cardinality string `json:"cardinality,omitempty" example:"one" enums:"one,many"` // для reference: одна ссылка или массив ссылок
+}
+
+// ReservedCollectionPrefix префикс служебных коллекций data service (история изменений,
+// снимки, ключи идемпотентности, журнал вебхуков, счетчики). Они хранятся в той же базе,
+// что и коллекции проектов, поэтому коллекции проекта с таким префиксом не создаются
+const ReservedCollectionPrefix = "_"
+
+// ReservedPaths первые сегменты пути, которые data service обрабатывает сам (GraphQL,
+// служебные маршруты). Коллекции и пользовательские маршруты с ними были бы недоступны.
+// Маршруты снимков и вебхуков (/_snapshots, /_webhooks) закрыты ReservedCollectionPrefix
+var ReservedPaths = []string{"graphql", "health", "generate", "swagger"}
+
+// IsReservedPath проверяет, что сегмент пути занят служебными маршрутами data service
+func IsReservedPath(segment string) bool {
+	return strings.HasPrefix(segment, ReservedCollectionPrefix) || slices.Contains(ReservedPaths, segment)
+}
+
+// ReservedQueryParams параметры запроса списка публичного API, которые не являются
+// фильтрами по полям (пагинация, сортировка, поиск, формат ответа)
+var ReservedQueryParams = map[string]bool{
+	"limit":    true,
+	"offset":   true,
+	"sort":     true,
+	"order":    true,
+	"q":        true,
+	"page":     true,
+	"per_page": true,
+	"cursor":   true,
+	"format":   true,
+	"as_of":    true,
+}
+
+// IsReservedQueryParam проверяет, что параметр запроса списка не фильтр по полю.
+// Параметры с префиксом "_" (_expand, _delay) также зарезервированы
+func IsReservedQueryParam(name string) bool {
+	return ReservedQueryParams[name] || strings.HasPrefix(name, "_")
+}
+
+// ValidateCollectionName проверяет имя коллекции проекта: оно не пустое, не начинается
+// с ReservedCollectionPrefix, не совпадает с ReservedPaths и допустимо как сегмент пути
+// и имя коллекции MongoDB
+func ValidateCollectionName(name string) error {
+	if name == "" {
+		return fmt.Errorf("collection name is required")
+	}
+	if strings.HasPrefix(name, ReservedCollectionPrefix) {
+		return fmt.Errorf("collection name must not start with %q", ReservedCollectionPrefix)
+	}
+	if slices.Contains(ReservedPaths, name) {
+		return fmt.Errorf("collection name %q is reserved", name)
+	}
+	if strings.HasPrefix(name, "system.") || strings.ContainsAny(name, "/$\x00") {
+		return fmt.Errorf("collection name %q is not allowed", name)
+	}
+	return nil
 }
 
-// CollectionConfig настройки генерации данных
+// Связность поля reference
+const (
+	CardinalityOne  = "one"
+	CardinalityMany = "many"
+)
+
+// CollectionConfig настройки генерации данных и поведения публичного API
 type CollectionConfig struct {
-	Count int    `json:"count" example:"10"` // Количество генерируемых записей
-	Seed  *int64 `json:"seed,omitempty"`     // Seed для воспроизводимости
+	Count    int    `json:"count" example:"10"`                 // Количество генерируемых записей
+	Seed     *int64 `json:"seed,omitempty"`                     // Seed для воспроизводимости
+	Envelope bool   `json:"envelope,omitempty" example:"false"` // Ответ списка в формате {data, meta} вместо массива
+	Strict   bool   `json:"strict,omitempty" example:"false"`   // Отклонять документы с полями, которых нет в схеме
+
+	Delay  *DelayConfig `json:"delay,omitempty"`  // Имитация задержки ответов публичного API
+	Faults []FaultRule  `json:"faults,omitempty"` // Имитация сбоев публичного API
+
+	Rules []ResponseRule `json:"rules,omitempty"` // Условные ответы по содержимому запроса
+}
+
+// Validate проверяет настройки задержки, сбоев и условных ответов
+func (c CollectionConfig) Validate() error {
+	if err := c.Delay.Validate(); err != nil {
+		return err
+	}
+	for i, rule := range c.Faults {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("fault rule %d: %w", i, err)
+		}
+	}
+	return validateRules(c.Rules)
+}
+
+// MaxDelay верхняя граница имитируемой задержки ответа, мс
+const MaxDelay = 30000
+
+// DelayConfig задержка ответа, мс. Применяется первый заданный вариант:
+// фиксированная задержка, равномерный диапазон min..max или распределение по перцентилям
+type DelayConfig struct {
+	Fixed       int            `json:"fixed,omitempty" example:"300"`
+	Min         int            `json:"min,omitempty" example:"100"`
+	Max         int            `json:"max,omitempty" example:"800"`
+	Percentiles map[string]int `json:"percentiles,omitempty"` // {"p50": 120, "p95": 600, "p99": 1500}
+}
+
+// Validate проверяет, что задержки неотрицательны, не превышают MaxDelay,
+// а ключи percentiles имеют вид p50, p99.9
+func (d *DelayConfig) Validate() error {
+	if d == nil {
+		return nil
+	}
+	if err := checkDelay("fixed", d.Fixed); err != nil {
+		return err
+	}
+	if err := checkDelay("min", d.Min); err != nil {
+		return err
+	}
+	if err := checkDelay("max", d.Max); err != nil {
+		return err
+	}
+	if d.Max > 0 && d.Min > d.Max {
+		return fmt.Errorf("delay min must not exceed max")
+	}
+	for key, value := range d.Percentiles {
+		if _, err := ParsePercentile(key); err != nil {
+			return err
+		}
+		if err := checkDelay("percentile "+key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParsePercentile разбирает ключ перцентиля: p95 -> 95
+func ParsePercentile(key string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimPrefix(key, "p"), 64)
+	if err != nil || !strings.HasPrefix(key, "p") || value <= 0 || value > 100 {
+		return 0, fmt.Errorf("invalid delay percentile %q: expected p1..p100", key)
+	}
+	return value, nil
+}
+
+// Виды имитируемых сбоев
+const (
+	FaultError    = "error"    // Ответ с кодом ошибки
+	FaultDrop     = "drop"     // Разрыв соединения без ответа
+	FaultTruncate = "truncate" // Ответ, оборванный на середине тела
+)
+
+// FaultRule правило имитации сбоя: с вероятностью probability запрос
+// к коллекции завершается ошибкой, разрывом соединения или обрезанным ответом.
+// При truncate запрос выполняется полностью, включая запись данных, обрывается
+// только ответ. Потоковые _stream и _export не обрываются
+type FaultRule struct {
+	Type        string            `json:"type,omitempty" example:"error" enums:"error,drop,truncate"` // По умолчанию error
+	Probability float64           `json:"probability" example:"0.1"`                                  // От 0 до 1
+	Methods     []string          `json:"methods,omitempty" example:"POST,PUT"`                       // Пусто - любые методы
+	Status      int               `json:"status,omitempty" example:"503"`                             // Для error, по умолчанию 500
+	Body        any               `json:"body,omitempty" swaggertype:"object"`                        // Для error, по умолчанию {"error": "..."}
+	Headers     map[string]string `json:"headers,omitempty"`                                          // Для error: Retry-After и т.п.
+}
+
+// Kind возвращает вид сбоя с учетом значения по умолчанию
+func (f FaultRule) Kind() string {
+	if f.Type == "" {
+		return FaultError
+	}
+	return f.Type
+}
+
+// Validate проверяет вид сбоя, вероятность и код ответа
+func (f FaultRule) Validate() error {
+	switch f.Kind() {
+	case FaultError, FaultDrop, FaultTruncate:
+	default:
+		return fmt.Errorf("unknown fault type %q", f.Type)
+	}
+	if f.Probability < 0 || f.Probability > 1 {
+		return fmt.Errorf("fault probability must be between 0 and 1")
+	}
+	if f.Status != 0 && (f.Status < 400 || f.Status > 599) {
+		return fmt.Errorf("fault status must be between 400 and 599")
+	}
+	return nil
+}
+
+func checkDelay(name string, ms int) error {
+	if ms < 0 || ms > MaxDelay {
+		return fmt.Errorf("delay %s must be between 0 and %d ms", name, MaxDelay)
+	}
+	return nil
 }
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Endpoint пользовательский маршрут проекта с шаблонным ответом (POST /checkout, GET /me).
+// Data service проверяет такие маршруты раньше CRUD коллекций
+type Endpoint struct {
+	ID          int64             `json:"id" example:"1"`
+	ProjectID   int64             `json:"project_id" example:"1"`
+	Method      string            `json:"method" example:"GET" enums:"GET,POST,PUT,PATCH,DELETE"`
+	Path        string            `json:"path" example:"/users/{id}/profile"` // Сегменты {name} - параметры пути
+	Description string            `json:"description" example:"Current user profile"`
+	Status      int               `json:"status" example:"200"`                                    // По умолчанию 200
+	Headers     map[string]string `json:"headers,omitempty"`                                       // По умолчанию Content-Type: application/json
+	Body        string            `json:"body" example:"{{ json (lookup \"users\" .Params.id) }}"` // Шаблон text/template
+	Rules       []ResponseRule    `json:"rules,omitempty"`                                         // Условные ответы вместо шаблона
+	IsActive    bool              `json:"is_active" example:"true"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// EndpointFuncs функции шаблона ответа (реализует data service):
+// json - значение в JSON, lookup "users" id - документ коллекции или nil,
+// find "posts" "userId" value - документы коллекции с полем, равным value,
+// now - текущее время, uuid - случайный UUID, default fallback value
+var EndpointFuncs = []string{"json", "lookup", "find", "now", "uuid", "default"}
+
+var endpointMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Validate проверяет метод, шаблон пути, код ответа, синтаксис шаблона тела и условные ответы
+func (e *Endpoint) Validate() error {
+	if !slices.Contains(endpointMethods, e.Method) {
+		return fmt.Errorf("method must be one of %s", strings.Join(endpointMethods, ", "))
+	}
+	if !strings.HasPrefix(e.Path, "/") || e.Path == "/" {
+		return fmt.Errorf("path must start with / and contain at least one segment")
+	}
+	if first := strings.Split(e.Path[1:], "/")[0]; IsReservedPath(first) {
+		return fmt.Errorf("path must not start with reserved segment /%s", first)
+	}
+	for _, segment := range strings.Split(e.Path[1:], "/") {
+		if segment == "" {
+			return fmt.Errorf("path must not contain empty segments")
+		}
+		if strings.HasPrefix(segment, "{") != strings.HasSuffix(segment, "}") || segment == "{}" {
+			return fmt.Errorf("invalid path parameter %q", segment)
+		}
+	}
+	if e.Status != 0 && (e.Status < 100 || e.Status > 599) {
+		return fmt.Errorf("status must be between 100 and 599")
+	}
+
+	funcs := template.FuncMap{}
+	for _, name := range EndpointFuncs {
+		funcs[name] = func(...any) any { return nil }
+	}
+	if _, err := template.New("body").Funcs(funcs).Parse(e.Body); err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+	return validateRules(e.Rules)
+}
@@ -8,9 +8,21 @@ type Project struct {
 	UserID           int64     `json:"user_id"`
 	Name             string    `json:"name"`
 	Description      string    `json:"description"`
-	APIKey           string    `json:"api_key"`  // Уникальный ключ для доступа к API проекта
-	BaseURL          string    `json:"base_url"` // https://<api_key>.mockingcode.org
+	APIKey           string    `json:"api_key"`           // Уникальный ключ для доступа к API проекта
+	BaseURL          string    `json:"base_url"`          // https://<api_key>.mockingcode.org
 	CollectionsCount int       `json:"collections_count"` // Количество коллекций в проекте
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
+
+	Settings ProjectSettings `json:"settings"` // Настройки публичного API проекта
+}
+
+// ProjectSettings настройки публичного API, общие для всех коллекций и маршрутов проекта
+type ProjectSettings struct {
+	Delay *DelayConfig `json:"delay,omitempty"` // Задержка ответов, если у коллекции не задана своя
+}
+
+// Validate проверяет настройки проекта
+func (s ProjectSettings) Validate() error {
+	return s.Delay.Validate()
 }
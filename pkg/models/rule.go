@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// ResponseRule условный ответ коллекции или пользовательского маршрута: если запрос
+// подходит по методу и выполнены все условия when, вместо обычного ответа
+// возвращаются status, headers и body
+type ResponseRule struct {
+	Name    string            `json:"name,omitempty" example:"large order"`
+	Methods []string          `json:"methods,omitempty" example:"POST"` // Пусто - любые методы
+	When    []RuleCondition   `json:"when"`
+	Status  int               `json:"status,omitempty" example:"422"` // По умолчанию 200
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty" swaggertype:"object"`
+}
+
+// Источники значений для условий правила
+const (
+	RuleSourceHeader = "header"
+	RuleSourceQuery  = "query"
+	RuleSourceBody   = "body"  // Поле JSON-тела, вложенные через точку: customer.email, items.0.price
+	RuleSourceParam  = "param" // Параметр пути маршрута; для коллекций - id документа
+)
+
+// RuleCondition условие правила: значение из запроса сравнивается с value
+type RuleCondition struct {
+	Source   string `json:"source" example:"body" enums:"header,query,body,param"`
+	Field    string `json:"field" example:"amount"`
+	Operator string `json:"operator,omitempty" example:"gt" enums:"eq,ne,gt,gte,lt,lte,in,like,exists"` // По умолчанию eq
+	Value    string `json:"value,omitempty" example:"1000"`                                             // Для in - через запятую, для like - regex
+}
+
+var (
+	ruleSources   = []string{RuleSourceHeader, RuleSourceQuery, RuleSourceBody, RuleSourceParam}
+	ruleOperators = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "like", "exists"}
+)
+
+// Validate проверяет код ответа и условия правила
+func (r ResponseRule) Validate() error {
+	if r.Status != 0 && (r.Status < 100 || r.Status > 599) {
+		return fmt.Errorf("status must be between 100 and 599")
+	}
+	for _, c := range r.When {
+		if !slices.Contains(ruleSources, c.Source) {
+			return fmt.Errorf("unknown condition source %q", c.Source)
+		}
+		if c.Field == "" {
+			return fmt.Errorf("condition field is required")
+		}
+		if c.Operator != "" && !slices.Contains(ruleOperators, c.Operator) {
+			return fmt.Errorf("unknown condition operator %q", c.Operator)
+		}
+		if c.Operator == "like" {
+			if _, err := regexp.Compile(c.Value); err != nil {
+				return fmt.Errorf("invalid like pattern %q: %w", c.Value, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRules проверяет список правил
+func validateRules(rules []ResponseRule) error {
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Webhook исходящее уведомление об изменении данных проекта. Data service отправляет
+// POST с JSON-телом на URL и подписывает его: X-Webhook-Signature: sha256=<hex>,
+// HMAC-SHA256 секрета от строки "<X-Webhook-Timestamp>.<тело>"
+type Webhook struct {
+	ID             int64     `json:"id" example:"1"`
+	ProjectID      int64     `json:"project_id" example:"1"`
+	CollectionName string    `json:"collection_name,omitempty" example:"orders"` // Пусто - все коллекции проекта
+	URL            string    `json:"url" example:"http://localhost:9000/hooks"`
+	Events         []string  `json:"events,omitempty" example:"document.created"` // Пусто - все события
+	Secret         string    `json:"secret" example:"whsec_5f2b..."`              // Ключ подписи
+	IsActive       bool      `json:"is_active" example:"true"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// События вебхуков
+const (
+	WebhookDocumentCreated     = "document.created"
+	WebhookDocumentUpdated     = "document.updated"
+	WebhookDocumentDeleted     = "document.deleted"
+	WebhookCollectionFlushed   = "collection.flushed"   // DELETE /{collection}
+	WebhookCollectionGenerated = "collection.generated" // POST /{collection}/_generate
+)
+
+// WebhookEvents все события вебхуков
+var WebhookEvents = []string{
+	WebhookDocumentCreated,
+	WebhookDocumentUpdated,
+	WebhookDocumentDeleted,
+	WebhookCollectionFlushed,
+	WebhookCollectionGenerated,
+}
+
+// Validate проверяет URL и список событий. Адрес получателя проверяется data service
+// при отправке: loopback, частные и link-local адреса отклоняются
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	for _, event := range w.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("unknown event %q, must be one of %s", event, strings.Join(WebhookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// Matches сообщает, подписан ли вебхук на событие коллекции
+func (w *Webhook) Matches(collectionName, event string) bool {
+	if w.CollectionName != "" && w.CollectionName != collectionName {
+		return false
+	}
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 
+	applogger "github.com/go-mockingcode/logger"
 	"github.com/go-mockingcode/project/internal/config"
 	"github.com/go-mockingcode/project/internal/database"
 	projectgrpc "github.com/go-mockingcode/project/internal/grpc"
@@ -14,7 +15,6 @@ import (
 	"github.com/go-mockingcode/project/internal/middleware"
 	"github.com/go-mockingcode/project/internal/repository"
 	"github.com/go-mockingcode/project/internal/service"
-	applogger "github.com/go-mockingcode/logger"
 	pb "github.com/go-mockingcode/proto"
 	"google.golang.org/grpc"
 
@@ -71,6 +71,14 @@ func main() {
 	if err := collectionRepo.InitSchema(); err != nil {
 		log.Fatal("Failed to init collections schema:", err)
 	}
+	endpointRepo := repository.NewEndpointRepository(db)
+	if err := endpointRepo.InitSchema(); err != nil {
+		log.Fatal("Failed to init endpoints schema:", err)
+	}
+	webhookRepo := repository.NewWebhookRepository(db)
+	if err := webhookRepo.InitSchema(); err != nil {
+		log.Fatal("Failed to init webhooks schema:", err)
+	}
 
 	// Init Services
 	projectService := service.NewProjectService(
@@ -83,11 +91,15 @@ func main() {
 		collectionRepo,
 		cfg.MaxSchemasPerProject,
 	)
+	endpointService := service.NewEndpointService(projectRepo, endpointRepo)
+	webhookService := service.NewWebhookService(projectRepo, webhookRepo)
 
 	// Init Handlers
 	projectHandler := handler.NewProjectHandler(projectService, cfg)
 	collectionHandler := handler.NewCollectionHandler(projectService, collectionService)
 	apiKeyHandler := handler.NewAPIKeyHandler(projectService)
+	endpointHandler := handler.NewEndpointHandler(endpointService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
 
 	// Route Settings
 	mux := http.NewServeMux()
@@ -101,10 +113,28 @@ func main() {
 	mux.HandleFunc("/projects/{id}", projectHandler.HandleProjectByID)
 	mux.HandleFunc("/projects/{id}/collections", collectionHandler.HandleProjectCollections)
 	mux.HandleFunc("/projects/{id}/collections/{collectionId}", collectionHandler.HandleProjectCollectionByID)
+	mux.HandleFunc("/projects/{id}/endpoints", endpointHandler.HandleProjectEndpoints)
+	mux.HandleFunc("/projects/{id}/endpoints/{endpointId}", endpointHandler.HandleProjectEndpointByID)
+	mux.HandleFunc("/projects/{id}/webhooks", webhookHandler.HandleProjectWebhooks)
+	mux.HandleFunc("/projects/{id}/webhooks/{webhookId}", webhookHandler.HandleProjectWebhookByID)
+	mux.HandleFunc("/projects/{id}/openapi.json", collectionHandler.GetProjectOpenAPI)
 
 	// API Keys validation (used by Data service, not through Gateway)
 	mux.HandleFunc("/api-keys/", apiKeyHandler.ValidateAPIKey)
 
+	// Project settings (used by Data service, not through Gateway)
+	mux.HandleFunc("/internal/projects/{id}/settings", projectHandler.GetProjectSettings)
+
+	// Collection schemas (used by Data service, not through Gateway)
+	mux.HandleFunc("/internal/projects/{id}/collections", collectionHandler.GetCollectionSchemas)
+	mux.HandleFunc("/internal/projects/{id}/collections/{name}", collectionHandler.GetCollectionSchema)
+
+	// Custom endpoints (used by Data service, not through Gateway)
+	mux.HandleFunc("/internal/projects/{id}/endpoints", endpointHandler.GetActiveEndpoints)
+
+	// Webhooks (used by Data service, not through Gateway)
+	mux.HandleFunc("/internal/projects/{id}/webhooks", webhookHandler.GetActiveWebhooks)
+
 	// Middleware Settings - extract user ID from X-User-ID header (set by Gateway)
 	handlerWithUserID := middleware.UserIDMiddleware(mux)
 
@@ -84,7 +84,7 @@ func (h *CollectionHandler) GetProjectCollections(w http.ResponseWriter, r *http
 
 // CreateProjectCollection godoc
 // @Summary Create collections for a project
-// @Description Create a new collection
+// @Description Create a new collection. Names starting with "_" are reserved for internal data service collections, graphql, health, generate and swagger for data service routes
 // @Tags collections
 // @Accept json
 // @Produce json
@@ -223,6 +223,114 @@ func (h *CollectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Requ
 	writeSuccessJson(w, http.StatusOK, map[string]string{"message": "Collection deleted successfully"})
 }
 
+// GetProjectOpenAPI godoc
+// @Summary Get OpenAPI document of the project mock API
+// @Description OpenAPI 3.1 document of the project public API generated from collection schemas: paths for every active collection, document schemas from field types, formats, options, min/max and required flags, server URL from the project base_url. Can be loaded into Swagger UI or client code generators
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} model.OpenAPIDocument
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/openapi.json [get]
+func (h *CollectionHandler) GetProjectOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := extractUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	projectID, err := extractProjectID(w, r)
+	if err != nil {
+		return
+	}
+
+	doc, err := h.collectionService.GetOpenAPIDocument(projectID, userID)
+	if err != nil {
+		writeErrorJson(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, doc)
+}
+
+// GetCollectionSchemas godoc
+// @Summary Get all collection schemas (internal)
+// @Description Get fields and config of all collections of a project. Used by Data service, not through Gateway
+// @Tags internal
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {array} model.Collection
+// @Failure 400 {object} map[string]string
+// @Router /internal/projects/{id}/collections [get]
+func (h *CollectionHandler) GetCollectionSchemas(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	collections, err := h.collectionService.GetCollectionSchemas(projectID)
+	if err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if collections == nil {
+		collections = []*model.Collection{}
+	}
+
+	writeSuccessJson(w, http.StatusOK, collections)
+}
+
+// GetCollectionSchema godoc
+// @Summary Get collection schema by name (internal)
+// @Description Get collection fields and config by project ID and collection name. Used by Data service, not through Gateway
+// @Tags internal
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param name path string true "Collection Name"
+// @Success 200 {object} model.Collection
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /internal/projects/{id}/collections/{name} [get]
+func (h *CollectionHandler) GetCollectionSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	collection, err := h.collectionService.GetCollectionByName(projectID, r.PathValue("name"))
+	if err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Коллекция без схемы - это нормально для гибридного подхода
+	if collection == nil {
+		writeErrorJson(w, http.StatusNotFound, "Collection not found")
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, collection)
+}
+
 func extractProjectAndCollectionID(w http.ResponseWriter, r *http.Request) (int64, int64, error) {
 	pathParts := strings.Split(r.URL.Path, "/")
 
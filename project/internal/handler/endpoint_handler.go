@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-mockingcode/project/internal/model"
+	"github.com/go-mockingcode/project/internal/service"
+)
+
+type EndpointHandler struct {
+	endpointService *service.EndpointService
+}
+
+func NewEndpointHandler(endpointService *service.EndpointService) *EndpointHandler {
+	return &EndpointHandler{
+		endpointService: endpointService,
+	}
+}
+
+// HandleProjectEndpoints handles /projects/{id}/endpoints endpoint for GET and POST methods
+func (h *EndpointHandler) HandleProjectEndpoints(w http.ResponseWriter, r *http.Request) {
+	userID, err := extractUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	projectID, err := extractProjectID(w, r)
+	if err != nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetProjectEndpoints(w, r, projectID, userID)
+	case http.MethodPost:
+		h.CreateEndpoint(w, r, projectID, userID)
+	default:
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetProjectEndpoints godoc
+// @Summary Get custom endpoints for a project
+// @Description Get list of user-defined routes with templated responses
+// @Tags endpoints
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/endpoints [get]
+func (h *EndpointHandler) GetProjectEndpoints(w http.ResponseWriter, r *http.Request, projectID int64, userID int64) {
+	endpoints, err := h.endpointService.GetProjectEndpoints(projectID, userID)
+	if err != nil {
+		writeErrorJson(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, map[string]any{
+		"endpoints": endpoints,
+		"count":     len(endpoints),
+	})
+}
+
+// CreateEndpoint godoc
+// @Summary Create custom endpoint
+// @Description Create a route (method + path pattern with {params}; the first segment must not start with "_" or be graphql, health, generate or swagger) whose response body is a Go text/template. Template data: .Method, .Path, .Params, .Query, .Headers, .Body; functions: json, lookup, find, now, uuid, default
+// @Tags endpoints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body model.CreateEndpointRequest true "Endpoint data"
+// @Success 201 {object} model.Endpoint
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /projects/{id}/endpoints [post]
+func (h *EndpointHandler) CreateEndpoint(w http.ResponseWriter, r *http.Request, projectID int64, userID int64) {
+	var req model.CreateEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	endpoint, err := h.endpointService.CreateEndpoint(projectID, userID, &req)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusCreated, endpoint)
+}
+
+// HandleProjectEndpointByID handles /projects/{id}/endpoints/{endpointId} for GET, PUT and DELETE methods
+func (h *EndpointHandler) HandleProjectEndpointByID(w http.ResponseWriter, r *http.Request) {
+	userID, err := extractUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	projectID, err := extractProjectID(w, r)
+	if err != nil {
+		return
+	}
+
+	endpointID, err := strconv.ParseInt(r.PathValue("endpointId"), 10, 64)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid endpoint ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetEndpoint(w, r, projectID, endpointID, userID)
+	case http.MethodPut:
+		h.UpdateEndpoint(w, r, projectID, endpointID, userID)
+	case http.MethodDelete:
+		h.DeleteEndpoint(w, r, projectID, endpointID, userID)
+	default:
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetEndpoint godoc
+// @Summary Get custom endpoint by ID
+// @Tags endpoints
+// @Produce json
+// @Security BearerAuth
+// @Param projectId path int true "Project ID"
+// @Param endpointId path int true "Endpoint ID"
+// @Success 200 {object} model.Endpoint
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{projectId}/endpoints/{endpointId} [get]
+func (h *EndpointHandler) GetEndpoint(w http.ResponseWriter, r *http.Request, projectID, endpointID, userID int64) {
+	endpoint, err := h.endpointService.GetEndpoint(endpointID, projectID, userID)
+	if err != nil {
+		writeErrorJson(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, endpoint)
+}
+
+// UpdateEndpoint godoc
+// @Summary Update custom endpoint
+// @Description Update endpoint method, path, response template or enable/disable it
+// @Tags endpoints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param projectId path int true "Project ID"
+// @Param endpointId path int true "Endpoint ID"
+// @Param request body model.UpdateEndpointRequest true "Endpoint data"
+// @Success 200 {object} model.Endpoint
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /projects/{projectId}/endpoints/{endpointId} [put]
+func (h *EndpointHandler) UpdateEndpoint(w http.ResponseWriter, r *http.Request, projectID, endpointID, userID int64) {
+	var req model.UpdateEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	endpoint, err := h.endpointService.UpdateEndpoint(endpointID, projectID, userID, &req)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, endpoint)
+}
+
+// DeleteEndpoint godoc
+// @Summary Delete custom endpoint
+// @Tags endpoints
+// @Produce json
+// @Security BearerAuth
+// @Param projectId path int true "Project ID"
+// @Param endpointId path int true "Endpoint ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /projects/{projectId}/endpoints/{endpointId} [delete]
+func (h *EndpointHandler) DeleteEndpoint(w http.ResponseWriter, r *http.Request, projectID, endpointID, userID int64) {
+	if err := h.endpointService.DeleteEndpoint(endpointID, projectID, userID); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, map[string]string{"message": "Endpoint deleted successfully"})
+}
+
+// GetActiveEndpoints godoc
+// @Summary Get active custom endpoints (internal)
+// @Description Get enabled custom endpoints of a project. Used by Data service, not through Gateway
+// @Tags internal
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {array} model.Endpoint
+// @Failure 400 {object} map[string]string
+// @Router /internal/projects/{id}/endpoints [get]
+func (h *EndpointHandler) GetActiveEndpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	endpoints, err := h.endpointService.GetActiveEndpoints(projectID)
+	if err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if endpoints == nil {
+		endpoints = []*model.Endpoint{}
+	}
+
+	writeSuccessJson(w, http.StatusOK, endpoints)
+}
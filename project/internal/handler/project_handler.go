@@ -64,7 +64,7 @@ func (h *ProjectHandler) GetUserProjects(w http.ResponseWriter, r *http.Request,
 		"projects": projects,
 		"count":    len(projects),
 		"limits": map[string]int{
-			"max_collections_per_project": h.config.MaxCollectionsPerProject,
+			"max_collections_per_project":  h.config.MaxCollectionsPerProject,
 			"max_documents_per_collection": h.config.MaxDocumentsPerCollection,
 		},
 	})
@@ -72,7 +72,7 @@ func (h *ProjectHandler) GetUserProjects(w http.ResponseWriter, r *http.Request,
 
 // CreateProject godoc
 // @Summary Create new project
-// @Description Create a new project for authenticated user
+// @Description Create a new project for authenticated user. settings.delay sets the simulated response delay for all collections and custom endpoints of the project
 // @Tags projects
 // @Accept json
 // @Produce json
@@ -94,6 +94,10 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request, u
 		writeErrorJson(w, http.StatusBadRequest, "Project name is required")
 		return
 	}
+	if err := req.Settings.Validate(); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	project, err := h.projectService.CreateProject(userID, &req)
 	if err != nil {
@@ -153,7 +157,7 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request, user
 
 // UpdateProject godoc
 // @Summary Update project
-// @Description Update project name and description
+// @Description Update project name, description and settings (settings are kept if omitted)
 // @Tags projects
 // @Accept json
 // @Produce json
@@ -171,6 +175,12 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request, u
 		writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	if req.Settings != nil {
+		if err := req.Settings.Validate(); err != nil {
+			writeErrorJson(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	project, err := h.projectService.UpdateProject(projectID, userID, &req)
 	if err != nil {
@@ -202,6 +212,41 @@ func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request, u
 	writeSuccessJson(w, http.StatusOK, map[string]string{"message": "Project deleted successfully"})
 }
 
+// GetProjectSettings godoc
+// @Summary Get project settings (internal)
+// @Description Get public API settings of a project (response delay). Used by Data service, not through Gateway
+// @Tags internal
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} model.ProjectSettings
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /internal/projects/{id}/settings [get]
+func (h *ProjectHandler) GetProjectSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	settings, err := h.projectService.GetProjectSettings(projectID)
+	if err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if settings == nil {
+		writeErrorJson(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, settings)
+}
+
 func extractUserID(w http.ResponseWriter, r *http.Request) (int64, error) {
 	// Get user ID from middleware (set by API Gateway via X-User-ID header)
 	userIDStr, ok := middleware.GetUserID(r.Context())
@@ -209,7 +254,7 @@ func extractUserID(w http.ResponseWriter, r *http.Request) (int64, error) {
 		writeErrorJson(w, http.StatusUnauthorized, "User not authenticated")
 		return 0, fmt.Errorf("no user ID in context")
 	}
-	
+
 	// Convert string to int64
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-mockingcode/project/internal/model"
+	"github.com/go-mockingcode/project/internal/service"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// HandleProjectWebhooks handles /projects/{id}/webhooks endpoint for GET and POST methods
+func (h *WebhookHandler) HandleProjectWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, err := extractUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	projectID, err := extractProjectID(w, r)
+	if err != nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetProjectWebhooks(w, r, projectID, userID)
+	case http.MethodPost:
+		h.CreateWebhook(w, r, projectID, userID)
+	default:
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetProjectWebhooks godoc
+// @Summary Get webhooks for a project
+// @Description Get list of outgoing webhooks notified about document changes
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/webhooks [get]
+func (h *WebhookHandler) GetProjectWebhooks(w http.ResponseWriter, r *http.Request, projectID int64, userID int64) {
+	webhooks, err := h.webhookService.GetProjectWebhooks(projectID, userID)
+	if err != nil {
+		writeErrorJson(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, map[string]any{
+		"webhooks": webhooks,
+		"count":    len(webhooks),
+	})
+}
+
+// CreateWebhook godoc
+// @Summary Create webhook
+// @Description Register a URL that receives signed JSON POST requests on document changes of the project or one collection. Events: document.created, document.updated, document.deleted, collection.flushed, collection.generated (empty - all). Body is signed with HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" in X-Webhook-Signature: sha256=<hex>. Failed deliveries are retried with exponential backoff; the delivery log is available in Data service at /{api_key}/_webhooks/deliveries
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body model.CreateWebhookRequest true "Webhook data"
+// @Success 201 {object} model.Webhook
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /projects/{id}/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request, projectID int64, userID int64) {
+	var req model.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(projectID, userID, &req)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusCreated, webhook)
+}
+
+// HandleProjectWebhookByID handles /projects/{id}/webhooks/{webhookId} for GET, PUT and DELETE methods
+func (h *WebhookHandler) HandleProjectWebhookByID(w http.ResponseWriter, r *http.Request) {
+	userID, err := extractUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	projectID, err := extractProjectID(w, r)
+	if err != nil {
+		return
+	}
+
+	webhookID, err := strconv.ParseInt(r.PathValue("webhookId"), 10, 64)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetWebhook(w, r, projectID, webhookID, userID)
+	case http.MethodPut:
+		h.UpdateWebhook(w, r, projectID, webhookID, userID)
+	case http.MethodDelete:
+		h.DeleteWebhook(w, r, projectID, webhookID, userID)
+	default:
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetWebhook godoc
+// @Summary Get webhook by ID
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param projectId path int true "Project ID"
+// @Param webhookId path int true "Webhook ID"
+// @Success 200 {object} model.Webhook
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{projectId}/webhooks/{webhookId} [get]
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request, projectID, webhookID, userID int64) {
+	webhook, err := h.webhookService.GetWebhook(webhookID, projectID, userID)
+	if err != nil {
+		writeErrorJson(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, webhook)
+}
+
+// UpdateWebhook godoc
+// @Summary Update webhook
+// @Description Update webhook URL, collection, events, secret or enable/disable it
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param projectId path int true "Project ID"
+// @Param webhookId path int true "Webhook ID"
+// @Param request body model.UpdateWebhookRequest true "Webhook data"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /projects/{projectId}/webhooks/{webhookId} [put]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request, projectID, webhookID, userID int64) {
+	var req model.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(webhookID, projectID, userID, &req)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, webhook)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param projectId path int true "Project ID"
+// @Param webhookId path int true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /projects/{projectId}/webhooks/{webhookId} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request, projectID, webhookID, userID int64) {
+	if err := h.webhookService.DeleteWebhook(webhookID, projectID, userID); err != nil {
+		writeErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeSuccessJson(w, http.StatusOK, map[string]string{"message": "Webhook deleted successfully"})
+}
+
+// GetActiveWebhooks godoc
+// @Summary Get active webhooks (internal)
+// @Description Get enabled webhooks of a project with their secrets. Used by Data service, not through Gateway
+// @Tags internal
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {array} model.Webhook
+// @Failure 400 {object} map[string]string
+// @Router /internal/projects/{id}/webhooks [get]
+func (h *WebhookHandler) GetActiveWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorJson(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeErrorJson(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	webhooks, err := h.webhookService.GetActiveWebhooks(projectID)
+	if err != nil {
+		writeErrorJson(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if webhooks == nil {
+		webhooks = []*model.Webhook{}
+	}
+
+	writeSuccessJson(w, http.StatusOK, webhooks)
+}
@@ -0,0 +1,29 @@
+package model
+
+import "github.com/go-mockingcode/models"
+
+type Endpoint = models.Endpoint
+type ResponseRule = models.ResponseRule
+
+// CreateEndpointRequest represents custom endpoint creation data
+type CreateEndpointRequest struct {
+	Method      string            `json:"method" example:"GET"`
+	Path        string            `json:"path" example:"/me"`
+	Description string            `json:"description" example:"Current user"`
+	Status      int               `json:"status,omitempty" example:"200"` // По умолчанию 200
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body" example:"{{ json (lookup \"users\" 1) }}"`
+	Rules       []ResponseRule    `json:"rules,omitempty"`
+}
+
+// UpdateEndpointRequest represents custom endpoint updating data
+type UpdateEndpointRequest struct {
+	Method      string            `json:"method,omitempty" example:"POST"`
+	Path        string            `json:"path,omitempty" example:"/checkout"`
+	Description string            `json:"description,omitempty"`
+	Status      *int              `json:"status,omitempty" example:"201"`
+	Headers     map[string]string `json:"headers,omitempty"` // Можно обновить заголовки
+	Body        *string           `json:"body,omitempty"`
+	Rules       []ResponseRule    `json:"rules,omitempty"` // Можно обновить условные ответы
+	IsActive    *bool             `json:"is_active,omitempty" example:"true"`
+}
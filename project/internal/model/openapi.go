@@ -0,0 +1,37 @@
+package model
+
+// OpenAPIDocument represents OpenAPI 3.1 document of the project public API
+type OpenAPIDocument struct {
+	OpenAPI    string            `json:"openapi" example:"3.1.0"`
+	Info       OpenAPIInfo       `json:"info"`
+	Servers    []OpenAPIServer   `json:"servers"`
+	Tags       []OpenAPITag      `json:"tags,omitempty"`
+	Paths      map[string]any    `json:"paths"`      // Пути коллекций: /users, /users/{id}
+	Components OpenAPIComponents `json:"components"` // Схемы документов, общие параметры и ответы
+}
+
+// OpenAPIInfo represents document metadata
+type OpenAPIInfo struct {
+	Title       string `json:"title" example:"My Project"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version" example:"2026-01-02T15:04:05Z"` // Время последнего изменения схем
+}
+
+// OpenAPIServer represents public API server
+type OpenAPIServer struct {
+	URL         string `json:"url" example:"https://abc123.api.mockingcode.com"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPITag represents operation group (one per collection)
+type OpenAPITag struct {
+	Name        string `json:"name" example:"users"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIComponents represents reusable document parts
+type OpenAPIComponents struct {
+	Schemas    map[string]any `json:"schemas"`
+	Parameters map[string]any `json:"parameters"`
+	Responses  map[string]any `json:"responses"`
+}
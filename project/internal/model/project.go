@@ -3,17 +3,20 @@ package model
 import "github.com/go-mockingcode/models"
 
 type Project = models.Project
+type ProjectSettings = models.ProjectSettings
 
 // CreateProjectRequest represents project creation data
 type CreateProjectRequest struct {
-	Name        string `json:"name" validate:"required,min=1,max=100"`
-	Description string `json:"description" validate:"max=500"`
+	Name        string          `json:"name" validate:"required,min=1,max=100"`
+	Description string          `json:"description" validate:"max=500"`
+	Settings    ProjectSettings `json:"settings"` // Задержка ответов для всех коллекций проекта
 }
 
 // UpdateProjectRequest represents project update data
 type UpdateProjectRequest struct {
-	Name        string `json:"name" validate:"required,min=1,max=100"`
-	Description string `json:"description" validate:"max=500"`
+	Name        string           `json:"name" validate:"required,min=1,max=100"`
+	Description string           `json:"description" validate:"max=500"`
+	Settings    *ProjectSettings `json:"settings,omitempty"` // Не передано - настройки не меняются
 }
 
 // ProjectResponse represents a project along with its collections
@@ -0,0 +1,22 @@
+package model
+
+import "github.com/go-mockingcode/models"
+
+type Webhook = models.Webhook
+
+// CreateWebhookRequest represents webhook creation data
+type CreateWebhookRequest struct {
+	CollectionName string   `json:"collection_name,omitempty" example:"orders"` // Пусто - все коллекции проекта
+	URL            string   `json:"url" example:"http://localhost:9000/hooks"`
+	Events         []string `json:"events,omitempty" example:"document.created"` // Пусто - все события
+	Secret         string   `json:"secret,omitempty"`                            // По умолчанию генерируется
+}
+
+// UpdateWebhookRequest represents webhook updating data
+type UpdateWebhookRequest struct {
+	CollectionName *string  `json:"collection_name,omitempty" example:"orders"` // Пустая строка - все коллекции
+	URL            string   `json:"url,omitempty" example:"http://localhost:9000/hooks"`
+	Events         []string `json:"events,omitempty"` // Можно обновить события
+	Secret         string   `json:"secret,omitempty"`
+	IsActive       *bool    `json:"is_active,omitempty" example:"true"`
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-mockingcode/project/internal/model"
+)
+
+type EndpointRepository struct {
+	db *sql.DB
+}
+
+func NewEndpointRepository(db *sql.DB) *EndpointRepository {
+	return &EndpointRepository{db: db}
+}
+
+func (r *EndpointRepository) InitSchema() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS endpoints (
+            id SERIAL PRIMARY KEY,
+            project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            method VARCHAR(10) NOT NULL,
+            path VARCHAR(255) NOT NULL,
+            description TEXT,
+            status INTEGER NOT NULL DEFAULT 200,
+            headers JSONB,
+            body TEXT,
+            rules JSONB,
+            is_active BOOLEAN DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(project_id, method, path)
+        );
+
+		CREATE INDEX IF NOT EXISTS idx_endpoints_project_id ON endpoints(project_id);
+
+		ALTER TABLE endpoints ADD COLUMN IF NOT EXISTS rules JSONB;`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const endpointColumns = `id, project_id, method, path, description, status, headers, body, rules, is_active, created_at, updated_at`
+
+func (r *EndpointRepository) CreateEndpoint(endpoint *model.Endpoint) error {
+	query := `
+        INSERT INTO endpoints (project_id, method, path, description, status, headers, body, rules, is_active, created_at, updated_at) 
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
+        RETURNING id`
+
+	headersJSON, _ := json.Marshal(endpoint.Headers)
+	rulesJSON, _ := json.Marshal(endpoint.Rules)
+
+	err := r.db.QueryRow(
+		query,
+		endpoint.ProjectID,
+		endpoint.Method,
+		endpoint.Path,
+		endpoint.Description,
+		endpoint.Status,
+		headersJSON,
+		endpoint.Body,
+		rulesJSON,
+		endpoint.IsActive,
+		endpoint.CreatedAt,
+		endpoint.UpdatedAt,
+	).Scan(&endpoint.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create endpoint: %v", err)
+	}
+
+	return nil
+}
+
+// GetProjectEndpoints возвращает маршруты проекта; activeOnly - только включенные (для Data service)
+func (r *EndpointRepository) GetProjectEndpoints(projectID int64, activeOnly bool) ([]*model.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM endpoints WHERE project_id = $1`
+	if activeOnly {
+		query += ` AND is_active`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*model.Endpoint
+	for rows.Next() {
+		endpoint, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// GetEndpointByID возвращает маршрут проекта по ID
+func (r *EndpointRepository) GetEndpointByID(endpointID int64, projectID int64) (*model.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM endpoints WHERE id = $1 AND project_id = $2`
+
+	endpoint, err := scanEndpoint(r.db.QueryRow(query, endpointID, projectID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find endpoint: %v", err)
+	}
+
+	return endpoint, nil
+}
+
+// UpdateEndpoint обновляет маршрут
+func (r *EndpointRepository) UpdateEndpoint(endpoint *model.Endpoint) error {
+	query := `
+        UPDATE endpoints 
+        SET method = $1, path = $2, description = $3, status = $4, headers = $5, body = $6, rules = $7, is_active = $8, updated_at = $9 
+        WHERE id = $10 AND project_id = $11`
+
+	headersJSON, _ := json.Marshal(endpoint.Headers)
+	rulesJSON, _ := json.Marshal(endpoint.Rules)
+
+	_, err := r.db.Exec(
+		query,
+		endpoint.Method,
+		endpoint.Path,
+		endpoint.Description,
+		endpoint.Status,
+		headersJSON,
+		endpoint.Body,
+		rulesJSON,
+		endpoint.IsActive,
+		time.Now(),
+		endpoint.ID,
+		endpoint.ProjectID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update endpoint: %v", err)
+	}
+	return nil
+}
+
+// DeleteEndpoint удаляет маршрут
+func (r *EndpointRepository) DeleteEndpoint(endpointID int64, projectID int64) error {
+	query := `DELETE FROM endpoints WHERE id = $1 AND project_id = $2`
+	result, err := r.db.Exec(query, endpointID, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete endpoint: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("endpoint not found")
+	}
+
+	return nil
+}
+
+// rowScanner общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEndpoint(row rowScanner) (*model.Endpoint, error) {
+	endpoint := &model.Endpoint{}
+	var description, body sql.NullString
+	var headersJSON, rulesJSON []byte
+
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.ProjectID,
+		&endpoint.Method,
+		&endpoint.Path,
+		&description,
+		&endpoint.Status,
+		&headersJSON,
+		&body,
+		&rulesJSON,
+		&endpoint.IsActive,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Description = description.String
+	endpoint.Body = body.String
+	json.Unmarshal(headersJSON, &endpoint.Headers)
+	json.Unmarshal(rulesJSON, &endpoint.Rules)
+
+	return endpoint, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/go-mockingcode/project/internal/model"
@@ -30,6 +31,8 @@ func (r *ProjectRepository) InitSchema() error {
 
         CREATE INDEX IF NOT EXISTS idx_projects_user_id ON projects(user_id);
         CREATE INDEX IF NOT EXISTS idx_projects_api_key ON projects(api_key);
+
+        ALTER TABLE projects ADD COLUMN IF NOT EXISTS settings JSONB;
     `
 
 	_, err := r.db.Exec(query)
@@ -38,10 +41,11 @@ func (r *ProjectRepository) InitSchema() error {
 
 func (r *ProjectRepository) CreateProject(project *model.Project) error {
 	query := `
-        INSERT INTO projects (user_id, name, description, api_key, base_url, created_at, updated_at) 
-        VALUES ($1, $2, $3, $4, $5, $6, $7) 
+        INSERT INTO projects (user_id, name, description, api_key, base_url, settings, created_at, updated_at) 
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
         RETURNING id`
 
+	settingsJSON, _ := json.Marshal(project.Settings)
 	err := r.db.QueryRow(
 		query,
 		project.UserID,
@@ -49,6 +53,7 @@ func (r *ProjectRepository) CreateProject(project *model.Project) error {
 		project.Description,
 		project.APIKey,
 		project.BaseURL,
+		settingsJSON,
 		project.CreatedAt,
 		project.UpdatedAt,
 	).Scan(&project.ID)
@@ -62,12 +67,12 @@ func (r *ProjectRepository) CreateProject(project *model.Project) error {
 
 func (r *ProjectRepository) GetUserProjects(userID int64) ([]*model.Project, error) {
 	query := `
-		SELECT p.id, p.user_id, p.name, p.description, p.api_key, p.base_url, p.created_at, p.updated_at,
+		SELECT p.id, p.user_id, p.name, p.description, p.api_key, p.base_url, p.settings, p.created_at, p.updated_at,
 		       COALESCE(COUNT(c.id), 0) as collections_count
         FROM projects p
 		LEFT JOIN collections c ON p.id = c.project_id AND c.is_active = true
 		WHERE p.user_id = $1 
-		GROUP BY p.id, p.user_id, p.name, p.description, p.api_key, p.base_url, p.created_at, p.updated_at
+		GROUP BY p.id, p.user_id, p.name, p.description, p.api_key, p.base_url, p.settings, p.created_at, p.updated_at
 		ORDER BY p.created_at DESC`
 
 	rows, err := r.db.Query(query, userID)
@@ -79,6 +84,7 @@ func (r *ProjectRepository) GetUserProjects(userID int64) ([]*model.Project, err
 	var projects []*model.Project
 	for rows.Next() {
 		project := &model.Project{}
+		var settingsJSON []byte
 		err := rows.Scan(
 			&project.ID,
 			&project.UserID,
@@ -86,6 +92,7 @@ func (r *ProjectRepository) GetUserProjects(userID int64) ([]*model.Project, err
 			&project.Description,
 			&project.APIKey,
 			&project.BaseURL,
+			&settingsJSON,
 			&project.CreatedAt,
 			&project.UpdatedAt,
 			&project.CollectionsCount,
@@ -93,6 +100,9 @@ func (r *ProjectRepository) GetUserProjects(userID int64) ([]*model.Project, err
 		if err != nil {
 			return nil, err
 		}
+		if err := unmarshalSettings(settingsJSON, &project.Settings); err != nil {
+			return nil, err
+		}
 		projects = append(projects, project)
 	}
 
@@ -101,12 +111,13 @@ func (r *ProjectRepository) GetUserProjects(userID int64) ([]*model.Project, err
 
 func (r *ProjectRepository) GetProjectByID(projectID int64, userID int64) (*model.Project, error) {
 	query := `
-		SELECT id, user_id, name, description, api_key, base_url, created_at, updated_at 
+		SELECT id, user_id, name, description, api_key, base_url, settings, created_at, updated_at 
 		FROM projects 
 		WHERE id = $1 
 			AND user_id = $2`
 
 	project := &model.Project{}
+	var settingsJSON []byte
 	err := r.db.QueryRow(query, projectID, userID).Scan(
 		&project.ID,
 		&project.UserID,
@@ -114,6 +125,7 @@ func (r *ProjectRepository) GetProjectByID(projectID int64, userID int64) (*mode
 		&project.Description,
 		&project.APIKey,
 		&project.BaseURL,
+		&settingsJSON,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
@@ -124,17 +136,21 @@ func (r *ProjectRepository) GetProjectByID(projectID int64, userID int64) (*mode
 	if err != nil {
 		return nil, err
 	}
+	if err := unmarshalSettings(settingsJSON, &project.Settings); err != nil {
+		return nil, err
+	}
 
 	return project, nil
 }
 
 func (r *ProjectRepository) GetProjectByAPIKey(apiKey string) (*model.Project, error) {
 	query := `
-		SELECT id, user_id, name, description, api_key, base_url, created_at, updated_at 
+		SELECT id, user_id, name, description, api_key, base_url, settings, created_at, updated_at 
         FROM projects 
 		WHERE api_key = $1`
 
 	project := &model.Project{}
+	var settingsJSON []byte
 	err := r.db.QueryRow(query, apiKey).Scan(
 		&project.ID,
 		&project.UserID,
@@ -142,6 +158,7 @@ func (r *ProjectRepository) GetProjectByAPIKey(apiKey string) (*model.Project, e
 		&project.Description,
 		&project.APIKey,
 		&project.BaseURL,
+		&settingsJSON,
 		&project.CreatedAt,
 		&project.UpdatedAt,
 	)
@@ -152,14 +169,18 @@ func (r *ProjectRepository) GetProjectByAPIKey(apiKey string) (*model.Project, e
 	if err != nil {
 		return nil, err
 	}
+	if err := unmarshalSettings(settingsJSON, &project.Settings); err != nil {
+		return nil, err
+	}
 
 	return project, nil
 }
 
 // UpdateProject обновляет проект
 func (r *ProjectRepository) UpdateProject(project *model.Project) error {
-	query := `UPDATE projects SET name = $1, description = $2, updated_at = $3 WHERE id = $4`
-	_, err := r.db.Exec(query, project.Name, project.Description, project.UpdatedAt, project.ID)
+	query := `UPDATE projects SET name = $1, description = $2, settings = $3, updated_at = $4 WHERE id = $5`
+	settingsJSON, _ := json.Marshal(project.Settings)
+	_, err := r.db.Exec(query, project.Name, project.Description, settingsJSON, project.UpdatedAt, project.ID)
 	return err
 }
 
@@ -169,3 +190,33 @@ func (r *ProjectRepository) DeleteProject(projectID int64) error {
 	_, err := r.db.Exec(query, projectID)
 	return err
 }
+
+// GetProjectSettings возвращает настройки публичного API проекта (nil, если проекта нет)
+func (r *ProjectRepository) GetProjectSettings(projectID int64) (*model.ProjectSettings, error) {
+	var settingsJSON []byte
+	err := r.db.QueryRow(`SELECT settings FROM projects WHERE id = $1`, projectID).Scan(&settingsJSON)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &model.ProjectSettings{}
+	if err := unmarshalSettings(settingsJSON, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// unmarshalSettings разбирает настройки проекта. У проектов, созданных до появления
+// настроек, колонка settings пуста
+func unmarshalSettings(data []byte, settings *model.ProjectSettings) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return fmt.Errorf("failed to parse project settings: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-mockingcode/project/internal/model"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) InitSchema() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS webhooks (
+            id SERIAL PRIMARY KEY,
+            project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+            collection_name VARCHAR(255) NOT NULL DEFAULT '',
+            url TEXT NOT NULL,
+            events JSONB,
+            secret VARCHAR(255) NOT NULL,
+            is_active BOOLEAN DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+		CREATE INDEX IF NOT EXISTS idx_webhooks_project_id ON webhooks(project_id);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const webhookColumns = `id, project_id, collection_name, url, events, secret, is_active, created_at, updated_at`
+
+func (r *WebhookRepository) CreateWebhook(webhook *model.Webhook) error {
+	query := `
+        INSERT INTO webhooks (project_id, collection_name, url, events, secret, is_active, created_at, updated_at) 
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+        RETURNING id`
+
+	eventsJSON, _ := json.Marshal(webhook.Events)
+
+	err := r.db.QueryRow(
+		query,
+		webhook.ProjectID,
+		webhook.CollectionName,
+		webhook.URL,
+		eventsJSON,
+		webhook.Secret,
+		webhook.IsActive,
+		webhook.CreatedAt,
+		webhook.UpdatedAt,
+	).Scan(&webhook.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %v", err)
+	}
+
+	return nil
+}
+
+// GetProjectWebhooks возвращает вебхуки проекта; activeOnly - только включенные (для Data service)
+func (r *WebhookRepository) GetProjectWebhooks(projectID int64, activeOnly bool) ([]*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE project_id = $1`
+	if activeOnly {
+		query += ` AND is_active`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*model.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// GetWebhookByID возвращает вебхук проекта по ID
+func (r *WebhookRepository) GetWebhookByID(webhookID int64, projectID int64) (*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND project_id = $2`
+
+	webhook, err := scanWebhook(r.db.QueryRow(query, webhookID, projectID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook: %v", err)
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook обновляет вебхук
+func (r *WebhookRepository) UpdateWebhook(webhook *model.Webhook) error {
+	query := `
+        UPDATE webhooks 
+        SET collection_name = $1, url = $2, events = $3, secret = $4, is_active = $5, updated_at = $6 
+        WHERE id = $7 AND project_id = $8`
+
+	eventsJSON, _ := json.Marshal(webhook.Events)
+
+	_, err := r.db.Exec(
+		query,
+		webhook.CollectionName,
+		webhook.URL,
+		eventsJSON,
+		webhook.Secret,
+		webhook.IsActive,
+		time.Now(),
+		webhook.ID,
+		webhook.ProjectID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %v", err)
+	}
+	return nil
+}
+
+// DeleteWebhook удаляет вебхук
+func (r *WebhookRepository) DeleteWebhook(webhookID int64, projectID int64) error {
+	query := `DELETE FROM webhooks WHERE id = $1 AND project_id = $2`
+	result, err := r.db.Exec(query, webhookID, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
+
+func scanWebhook(row rowScanner) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	var eventsJSON []byte
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.ProjectID,
+		&webhook.CollectionName,
+		&webhook.URL,
+		&eventsJSON,
+		&webhook.Secret,
+		&webhook.IsActive,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(eventsJSON, &webhook.Events)
+
+	return webhook, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-mockingcode/models"
 	"github.com/go-mockingcode/project/internal/model"
 	"github.com/go-mockingcode/project/internal/repository"
 )
@@ -34,6 +35,13 @@ func (s *CollectionService) CreateCollection(projectID int64, userID int64, req
 		return nil, errors.New("project not found")
 	}
 
+	if err := models.ValidateCollectionName(req.Name); err != nil {
+		return nil, err
+	}
+	if err := req.Config.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Проверяем лимит коллекций
 	collections, err := s.collectionRepo.GetProjectCollections(projectID)
 	if err != nil {
@@ -82,6 +90,11 @@ func (s *CollectionService) GetProjectCollections(projectID int64, userID int64)
 	return s.collectionRepo.GetProjectCollections(projectID)
 }
 
+// GetCollectionSchemas возвращает все коллекции проекта (для Data service, без проверки owner)
+func (s *CollectionService) GetCollectionSchemas(projectID int64) ([]*model.Collection, error) {
+	return s.collectionRepo.GetProjectCollections(projectID)
+}
+
 // GetCollectionByName возвращает коллекцию по имени (для gRPC, без проверки owner)
 func (s *CollectionService) GetCollectionByName(projectID int64, collectionName string) (*model.Collection, error) {
 	return s.collectionRepo.GetCollectionByName(projectID, collectionName)
@@ -123,6 +136,9 @@ func (s *CollectionService) UpdateCollection(collectionID int64, projectID int64
 
 	// Обновляем только переданные поля
 	if req.Name != "" {
+		if err := models.ValidateCollectionName(req.Name); err != nil {
+			return nil, err
+		}
 		collection.Name = req.Name
 	}
 	if req.Description != "" {
@@ -132,6 +148,9 @@ func (s *CollectionService) UpdateCollection(collectionID int64, projectID int64
 		collection.Fields = req.Fields
 	}
 	if req.Config != nil {
+		if err := req.Config.Validate(); err != nil {
+			return nil, err
+		}
 		collection.Config = *req.Config
 	}
 	if req.IsActive != nil {
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-mockingcode/project/internal/model"
+	"github.com/go-mockingcode/project/internal/repository"
+)
+
+type EndpointService struct {
+	projectRepo  *repository.ProjectRepository
+	endpointRepo *repository.EndpointRepository
+}
+
+func NewEndpointService(projectRepo *repository.ProjectRepository, endpointRepo *repository.EndpointRepository) *EndpointService {
+	return &EndpointService{
+		projectRepo:  projectRepo,
+		endpointRepo: endpointRepo,
+	}
+}
+
+// CreateEndpoint создает пользовательский маршрут проекта
+func (s *EndpointService) CreateEndpoint(projectID int64, userID int64, req *model.CreateEndpointRequest) (*model.Endpoint, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	endpoint := &model.Endpoint{
+		ProjectID:   projectID,
+		Method:      strings.ToUpper(req.Method),
+		Path:        req.Path,
+		Description: req.Description,
+		Status:      req.Status,
+		Headers:     req.Headers,
+		Body:        req.Body,
+		Rules:       req.Rules,
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if endpoint.Status == 0 {
+		endpoint.Status = http.StatusOK
+	}
+
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.endpointRepo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// GetProjectEndpoints возвращает все маршруты проекта
+func (s *EndpointService) GetProjectEndpoints(projectID int64, userID int64) ([]*model.Endpoint, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.endpointRepo.GetProjectEndpoints(projectID, false)
+}
+
+// GetActiveEndpoints возвращает включенные маршруты проекта (для Data service, без проверки owner)
+func (s *EndpointService) GetActiveEndpoints(projectID int64) ([]*model.Endpoint, error) {
+	return s.endpointRepo.GetProjectEndpoints(projectID, true)
+}
+
+// GetEndpoint возвращает маршрут по ID
+func (s *EndpointService) GetEndpoint(endpointID int64, projectID int64, userID int64) (*model.Endpoint, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.endpointRepo.GetEndpointByID(endpointID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, errors.New("endpoint not found")
+	}
+
+	return endpoint, nil
+}
+
+// UpdateEndpoint обновляет маршрут
+func (s *EndpointService) UpdateEndpoint(endpointID int64, projectID int64, userID int64, req *model.UpdateEndpointRequest) (*model.Endpoint, error) {
+	endpoint, err := s.GetEndpoint(endpointID, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Обновляем только переданные поля
+	if req.Method != "" {
+		endpoint.Method = strings.ToUpper(req.Method)
+	}
+	if req.Path != "" {
+		endpoint.Path = req.Path
+	}
+	if req.Description != "" {
+		endpoint.Description = req.Description
+	}
+	if req.Status != nil {
+		endpoint.Status = *req.Status
+	}
+	if req.Headers != nil {
+		endpoint.Headers = req.Headers
+	}
+	if req.Body != nil {
+		endpoint.Body = *req.Body
+	}
+	if req.Rules != nil {
+		endpoint.Rules = req.Rules
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint.UpdatedAt = time.Now()
+
+	if err := s.endpointRepo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// DeleteEndpoint удаляет маршрут
+func (s *EndpointService) DeleteEndpoint(endpointID int64, projectID int64, userID int64) error {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return err
+	}
+
+	return s.endpointRepo.DeleteEndpoint(endpointID, projectID)
+}
+
+// checkProject проверяет, что проект существует и принадлежит пользователю
+func (s *EndpointService) checkProject(projectID int64, userID int64) error {
+	project, err := s.projectRepo.GetProjectByID(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return errors.New("project not found")
+	}
+	return nil
+}
@@ -0,0 +1,601 @@
+package service
+
+import (
+	"cmp"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mockingcode/models"
+	"github.com/go-mockingcode/project/internal/model"
+)
+
+// openAPIVersion версия спецификации OpenAPI генерируемого документа
+const openAPIVersion = "3.1.0"
+
+// openAPISharedSchemas имена общих схем документа, которые не достаются коллекциям
+var openAPISharedSchemas = []string{"Error", "ValidationError", "FieldViolation", "PaginationMeta", "Message", "FlushResult", "JSONPatch"}
+
+// openAPIFormats форматы полей, у которых есть стандартный аналог в JSON Schema.
+// Остальные форматы (name, phone, city...) влияют только на генерацию данных
+var openAPIFormats = map[string]string{
+	"email": "email",
+	"url":   "uri",
+	"uuid":  "uuid",
+}
+
+// GetOpenAPIDocument возвращает документ OpenAPI 3.1 публичного API проекта:
+// пути каждой активной коллекции и схемы документов, построенные по полям коллекций
+func (s *CollectionService) GetOpenAPIDocument(projectID int64, userID int64) (*model.OpenAPIDocument, error) {
+	project, err := s.projectRepo.GetProjectByID(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.New("project not found")
+	}
+
+	collections, err := s.collectionRepo.GetProjectCollections(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildOpenAPIDocument(project, collections), nil
+}
+
+// buildOpenAPIDocument строит документ OpenAPI по проекту и его коллекциям
+func buildOpenAPIDocument(project *model.Project, collections []*model.Collection) *model.OpenAPIDocument {
+	doc := &model.OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: model.OpenAPIInfo{
+			Title:       project.Name,
+			Description: project.Description,
+		},
+		Servers: []model.OpenAPIServer{{URL: project.BaseURL, Description: "Mock API"}},
+		Paths:   map[string]any{},
+		Components: model.OpenAPIComponents{
+			Schemas:    openAPISharedSchemaSet(),
+			Parameters: openAPISharedParameters(),
+			Responses:  openAPISharedResponses(),
+		},
+	}
+
+	used := map[string]bool{}
+	for _, name := range openAPISharedSchemas {
+		used[name] = true
+	}
+
+	// Старые коллекции первыми: при совпадении имен схем суффикс получает более новая
+	sorted := slices.Clone(collections)
+	slices.SortFunc(sorted, func(a, b *model.Collection) int { return cmp.Compare(a.ID, b.ID) })
+
+	// Версия документа меняется вместе со схемами, по ней клиенты видят, что пора перегенерировать код
+	updated := project.UpdatedAt
+	for _, collection := range sorted {
+		if collection.UpdatedAt.After(updated) {
+			updated = collection.UpdatedAt
+		}
+		// Запросы к неактивным коллекциям отклоняются gateway
+		if !collection.IsActive {
+			continue
+		}
+		addOpenAPICollection(doc, collection, openAPISchemaName(collection.Name, used))
+	}
+	doc.Info.Version = updated.UTC().Format(time.RFC3339)
+
+	return doc
+}
+
+// addOpenAPICollection добавляет пути, схемы документов и тег коллекции.
+// name - имя схемы документа (users -> Users), от него образованы UsersInput, UsersPatch и UsersPage
+func addOpenAPICollection(doc *model.OpenAPIDocument, collection *model.Collection, name string) {
+	schemas := doc.Components.Schemas
+	schemas[name] = openAPIDocumentSchema(collection)
+	schemas[name+"Input"] = openAPIInputSchema(collection, true)
+	schemas[name+"Patch"] = openAPIInputSchema(collection, false)
+
+	list := map[string]any{"type": "array", "items": schemaRef(name)}
+	if collection.Config.Envelope {
+		schemas[name+"Page"] = map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"data": list,
+				"meta": schemaRef("PaginationMeta"),
+			},
+			"required": []string{"data", "meta"},
+		}
+		list = schemaRef(name + "Page")
+	}
+
+	doc.Tags = append(doc.Tags, model.OpenAPITag{Name: collection.Name, Description: collection.Description})
+	tags := []string{collection.Name}
+
+	listParameters := parameterRefs("limit", "offset", "page", "perPage", "cursor", "sort", "order", "q", "expand", "embed", "asOf")
+	listParameters = append(listParameters, openAPIFilterParameters(collection)...)
+
+	collectionPath := "/" + url.PathEscape(collection.Name)
+	doc.Paths[collectionPath] = map[string]any{
+		"get": map[string]any{
+			"tags":        tags,
+			"operationId": "list" + name,
+			"summary":     "List " + collection.Name,
+			"parameters":  listParameters,
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "Documents page",
+					"headers": map[string]any{
+						"X-Total-Count": header("integer", "Total number of matching documents"),
+						"Link":          header("string", "RFC 5988 pagination links: first, prev, next, last"),
+						"X-Next-Cursor": header("string", "Cursor of the next page (cursor mode)"),
+					},
+					"content": jsonContent(list),
+				},
+				"400": responseRef("BadRequest"),
+			},
+		},
+		"post": map[string]any{
+			"tags":        tags,
+			"operationId": "create" + name,
+			"summary":     "Create " + collection.Name + " document",
+			"parameters": []any{map[string]any{
+				"name":        "Idempotency-Key",
+				"in":          "header",
+				"description": "Key for safe retries: a repeated request returns the original response",
+				"schema":      map[string]any{"type": "string"},
+			}},
+			"requestBody": requestBody(jsonContent(schemaRef(name + "Input"))),
+			"responses": map[string]any{
+				"201": documentResponse(name, "Created document"),
+				"400": responseRef("BadRequest"),
+				"409": responseRef("Conflict"),
+				"422": responseRef("ValidationFailed"),
+			},
+		},
+		"delete": map[string]any{
+			"tags":        tags,
+			"operationId": "flush" + name,
+			"summary":     "Delete all " + collection.Name + " documents",
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "Collection flushed",
+					"content":     jsonContent(schemaRef("FlushResult")),
+				},
+				"400": responseRef("BadRequest"),
+			},
+		},
+	}
+
+	doc.Paths[collectionPath+"/{id}"] = map[string]any{
+		"parameters": parameterRefs("documentId"),
+		"get": map[string]any{
+			"tags":        tags,
+			"operationId": "get" + name,
+			"summary":     "Get " + collection.Name + " document",
+			"parameters":  parameterRefs("expand", "embed", "asOf"),
+			"responses": map[string]any{
+				"200": documentResponse(name, "Document"),
+				"304": map[string]any{"description": "Not modified (If-None-Match, If-Modified-Since)"},
+				"404": responseRef("NotFound"),
+			},
+		},
+		"put": map[string]any{
+			"tags":        tags,
+			"operationId": "replace" + name,
+			"summary":     "Replace " + collection.Name + " document",
+			"parameters":  parameterRefs("ifMatch"),
+			"requestBody": requestBody(jsonContent(schemaRef(name + "Input"))),
+			"responses": map[string]any{
+				"200": documentResponse(name, "Updated document"),
+				"400": responseRef("BadRequest"),
+				"404": responseRef("NotFound"),
+				"409": responseRef("Conflict"),
+				"412": responseRef("PreconditionFailed"),
+				"422": responseRef("ValidationFailed"),
+			},
+		},
+		"patch": map[string]any{
+			"tags":        tags,
+			"operationId": "patch" + name,
+			"summary":     "Partially update " + collection.Name + " document",
+			"description": "JSON Merge Patch (RFC 7396, null removes a field) or JSON Patch (RFC 6902). Plain application/json is treated as merge patch",
+			"parameters":  parameterRefs("ifMatch"),
+			"requestBody": requestBody(map[string]any{
+				"application/merge-patch+json": map[string]any{"schema": schemaRef(name + "Patch")},
+				"application/json":             map[string]any{"schema": schemaRef(name + "Patch")},
+				"application/json-patch+json":  map[string]any{"schema": schemaRef("JSONPatch")},
+			}),
+			"responses": map[string]any{
+				"200": documentResponse(name, "Updated document"),
+				"400": responseRef("BadRequest"),
+				"404": responseRef("NotFound"),
+				"409": responseRef("Conflict"),
+				"412": responseRef("PreconditionFailed"),
+				"415": responseRef("UnsupportedMediaType"),
+				"422": responseRef("ValidationFailed"),
+			},
+		},
+		"delete": map[string]any{
+			"tags":        tags,
+			"operationId": "delete" + name,
+			"summary":     "Delete " + collection.Name + " document",
+			"parameters":  parameterRefs("ifMatch"),
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "Document deleted",
+					"content":     jsonContent(schemaRef("Message")),
+				},
+				"400": responseRef("BadRequest"),
+				"412": responseRef("PreconditionFailed"),
+			},
+		},
+	}
+}
+
+// openAPIDocumentSchema схема документа в ответах: id и поля коллекции.
+// Коллекция без полей описывается как документ с произвольными полями
+func openAPIDocumentSchema(collection *model.Collection) map[string]any {
+	properties := map[string]any{
+		"id": map[string]any{
+			"type":        "integer",
+			"format":      "int64",
+			"readOnly":    true,
+			"description": "Document id assigned by the service",
+		},
+	}
+	required := []string{"id"}
+	for _, field := range collection.Fields {
+		if field.Name == "" || field.Name == "id" {
+			continue
+		}
+		properties[field.Name] = openAPIFieldSchema(field)
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// openAPIInputSchema схема тела POST и PUT (full) или merge patch (без обязательных полей).
+// id назначается сервисом и в теле не описывается. Поля вне схемы запрещены только
+// для коллекций со strict
+func openAPIInputSchema(collection *model.Collection, full bool) map[string]any {
+	schema := map[string]any{"type": "object"}
+
+	properties := map[string]any{}
+	var required []string
+	for _, field := range collection.Fields {
+		if field.Name == "" || field.Name == "id" {
+			continue
+		}
+		if full {
+			properties[field.Name] = openAPIFieldSchema(field)
+		} else {
+			properties[field.Name] = nullableSchema(openAPIFieldSchema(field))
+		}
+		if full && field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	if len(properties) > 0 {
+		schema["properties"] = properties
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	if collection.Config.Strict && len(properties) > 0 {
+		schema["additionalProperties"] = false
+	}
+	if !full {
+		schema["description"] = "Fields to change, null removes a field"
+	}
+
+	return schema
+}
+
+// openAPIFieldSchema схема значения поля по его типу, формату, вариантам и границам
+func openAPIFieldSchema(field models.FieldTemplate) map[string]any {
+	switch field.Type {
+	case "number":
+		schema := map[string]any{"type": "number"}
+		if field.Min != nil {
+			schema["minimum"] = *field.Min
+		}
+		if field.Max != nil {
+			schema["maximum"] = *field.Max
+		}
+		return schema
+
+	case "boolean":
+		return map[string]any{"type": "boolean"}
+
+	case "date":
+		return map[string]any{
+			"type":        "string",
+			"format":      "date-time",
+			"description": "RFC 3339 date-time, requests also accept YYYY-MM-DD",
+		}
+
+	case "reference":
+		id := map[string]any{
+			"type":        []string{"integer", "string"},
+			"description": "Id of a " + field.Reference + " document",
+		}
+		if field.Cardinality == models.CardinalityMany {
+			return map[string]any{"type": "array", "items": id}
+		}
+		return id
+
+	case "string", "":
+		schema := map[string]any{"type": "string"}
+		if format, ok := openAPIFormats[field.Format]; ok {
+			schema["format"] = format
+		}
+		if len(field.Options) > 0 {
+			schema["enum"] = field.Options
+		}
+		return schema
+
+	default:
+		// Значения полей неизвестных типов не проверяются публичным API
+		return map[string]any{}
+	}
+}
+
+// nullableSchema разрешает null в схеме значения (null в merge patch удаляет поле)
+func nullableSchema(schema map[string]any) map[string]any {
+	switch t := schema["type"].(type) {
+	case string:
+		schema["type"] = []string{t, "null"}
+	case []string:
+		schema["type"] = append(t, "null")
+	}
+	if options, ok := schema["enum"].([]string); ok {
+		values := make([]any, 0, len(options)+1)
+		for _, option := range options {
+			values = append(values, option)
+		}
+		schema["enum"] = append(values, nil)
+	}
+	return schema
+}
+
+// openAPIFilterParameters фильтры списка по равенству полей коллекции. Операторы
+// сравнения (?price_gte=) описываются в тексте, чтобы не умножать число параметров
+func openAPIFilterParameters(collection *model.Collection) []any {
+	var parameters []any
+	for _, field := range collection.Fields {
+		if field.Name == "" || models.IsReservedQueryParam(field.Name) {
+			continue
+		}
+
+		schema := openAPIFieldSchema(field)
+		if items, ok := schema["items"]; ok {
+			// Для массива ссылок равенство означает "содержит"
+			schema = items.(map[string]any)
+		}
+
+		parameters = append(parameters, map[string]any{
+			"name":        field.Name,
+			"in":          "query",
+			"description": "Filter by " + field.Name + ". Operators: " + field.Name + "_ne, _gt, _gte, _lt, _lte, _in (comma separated), _like (regex), _exists (true/false)",
+			"schema":      schema,
+		})
+	}
+	return parameters
+}
+
+// openAPISchemaName имя схемы документа коллекции (order_items -> OrderItems), уникальное
+// вместе с производными именами. Занятые имена отмечаются в used
+func openAPISchemaName(collectionName string, used map[string]bool) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(collectionName, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	base := b.String()
+	if base == "" || base[0] >= '0' && base[0] <= '9' {
+		base = "Collection" + base
+	}
+
+	name := base
+	for i := 2; ; i++ {
+		free := true
+		for _, suffix := range []string{"", "Input", "Patch", "Page"} {
+			if used[name+suffix] {
+				free = false
+				break
+			}
+		}
+		if free {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+
+	for _, suffix := range []string{"", "Input", "Patch", "Page"} {
+		used[name+suffix] = true
+	}
+	return name
+}
+
+// openAPISharedSchemaSet общие схемы ответов публичного API
+func openAPISharedSchemaSet() map[string]any {
+	return map[string]any{
+		"Error": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"error": map[string]any{"type": "string"}},
+			"required":   []string{"error"},
+		},
+		"FieldViolation": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"field": map[string]any{"type": "string"},
+				"rule": map[string]any{
+					"type": "string",
+					"enum": []string{"required", "type", "min", "max", "options", "format", "unknown"},
+				},
+				"message": map[string]any{"type": "string"},
+			},
+			"required": []string{"field", "rule", "message"},
+		},
+		"ValidationError": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"error":      map[string]any{"type": "string"},
+				"violations": map[string]any{"type": "array", "items": schemaRef("FieldViolation")},
+			},
+			"required": []string{"error"},
+		},
+		"PaginationMeta": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"total":       map[string]any{"type": "integer"},
+				"limit":       map[string]any{"type": "integer"},
+				"offset":      map[string]any{"type": "integer"},
+				"page":        map[string]any{"type": "integer"},
+				"per_page":    map[string]any{"type": "integer"},
+				"total_pages": map[string]any{"type": "integer"},
+				"next_cursor": map[string]any{"type": "string"},
+			},
+			"required": []string{"total", "limit", "offset"},
+		},
+		"Message": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"message": map[string]any{"type": "string"}},
+			"required":   []string{"message"},
+		},
+		"FlushResult": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"message":       map[string]any{"type": "string"},
+				"deleted_count": map[string]any{"type": "integer"},
+			},
+			"required": []string{"message", "deleted_count"},
+		},
+		"JSONPatch": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"op":    map[string]any{"type": "string", "enum": []string{"add", "remove", "replace", "move", "copy", "test"}},
+					"path":  map[string]any{"type": "string"},
+					"from":  map[string]any{"type": "string"},
+					"value": map[string]any{},
+				},
+				"required": []string{"op", "path"},
+			},
+		},
+	}
+}
+
+// openAPISharedParameters общие параметры запросов к коллекциям
+func openAPISharedParameters() map[string]any {
+	integer := map[string]any{"type": "integer", "minimum": 0}
+	text := map[string]any{"type": "string"}
+
+	return map[string]any{
+		"documentId": map[string]any{
+			"name":     "id",
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "integer", "format": "int64"},
+		},
+		"limit":   queryParameter("limit", "Page size", integer),
+		"offset":  queryParameter("offset", "Number of documents to skip", integer),
+		"page":    queryParameter("page", "Page number (alternative to limit/offset)", map[string]any{"type": "integer", "minimum": 1}),
+		"perPage": queryParameter("per_page", "Page size (alternative to limit/offset)", integer),
+		"cursor":  queryParameter("cursor", "Cursor pagination: empty for the first page, then next_cursor from the previous response", text),
+		"sort":    queryParameter("sort", "Sort fields, comma separated, '-' prefix for descending: -price,name", text),
+		"order":   queryParameter("order", "Default sort order for keys without prefix", map[string]any{"type": "string", "enum": []string{"asc", "desc"}}),
+		"q":       queryParameter("q", "Full-text search across string fields", text),
+		"expand":  queryParameter("_expand", "Inline documents referenced by reference fields: _expand=user (field userId or user)", text),
+		"embed":   queryParameter("_embed", "Inline documents of another collection referencing the document: _embed=comments", text),
+		"asOf":    queryParameter("as_of", "State at a past moment from the change history (RFC 3339 or YYYY-MM-DD)", text),
+		"ifMatch": map[string]any{
+			"name":        "If-Match",
+			"in":          "header",
+			"description": "ETag of the document version being changed",
+			"schema":      text,
+		},
+	}
+}
+
+// openAPISharedResponses общие ответы с ошибками
+func openAPISharedResponses() map[string]any {
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{"description": description, "content": jsonContent(schemaRef("Error"))}
+	}
+
+	return map[string]any{
+		"BadRequest":           errorResponse("Invalid request"),
+		"NotFound":             errorResponse("Document not found"),
+		"Conflict":             errorResponse("Unique field value already exists"),
+		"PreconditionFailed":   errorResponse("If-Match does not match the current document version"),
+		"UnsupportedMediaType": errorResponse("Unsupported patch format"),
+		"ValidationFailed": map[string]any{
+			"description": "Document violates the collection schema",
+			"content":     jsonContent(schemaRef("ValidationError")),
+		},
+	}
+}
+
+func documentResponse(name, description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"headers": map[string]any{
+			"ETag":          header("string", "Document version tag"),
+			"Last-Modified": header("string", "Document modification time"),
+		},
+		"content": jsonContent(schemaRef(name)),
+	}
+}
+
+func queryParameter(name, description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"name":        name,
+		"in":          "query",
+		"description": description,
+		"schema":      schema,
+	}
+}
+
+func parameterRefs(names ...string) []any {
+	refs := make([]any, len(names))
+	for i, name := range names {
+		refs[i] = map[string]any{"$ref": "#/components/parameters/" + name}
+	}
+	return refs
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func responseRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/responses/" + name}
+}
+
+func header(schemaType, description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"schema":      map[string]any{"type": schemaType},
+	}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+func requestBody(content map[string]any) map[string]any {
+	return map[string]any{"required": true, "content": content}
+}
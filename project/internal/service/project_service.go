@@ -51,6 +51,7 @@ func (s *ProjectService) CreateProject(userID int64, req *model.CreateProjectReq
 		Description: req.Description,
 		APIKey:      apiKey,
 		BaseURL:     s.generateBaseURL(apiKey),
+		Settings:    req.Settings,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -84,6 +85,11 @@ func (s *ProjectService) GetProjectByAPIKey(apiKey string) (*model.Project, erro
 	return s.projectRepo.GetProjectByAPIKey(apiKey)
 }
 
+// GetProjectSettings возвращает настройки публичного API проекта (для data service)
+func (s *ProjectService) GetProjectSettings(projectID int64) (*model.ProjectSettings, error) {
+	return s.projectRepo.GetProjectSettings(projectID)
+}
+
 // UpdateProject обновляет проект
 func (s *ProjectService) UpdateProject(projectID int64, userID int64, req *model.UpdateProjectRequest) (*model.Project, error) {
 	// Получаем проект (проверяем владельца)
@@ -98,6 +104,9 @@ func (s *ProjectService) UpdateProject(projectID int64, userID int64, req *model
 	// Обновляем поля
 	project.Name = req.Name
 	project.Description = req.Description
+	if req.Settings != nil {
+		project.Settings = *req.Settings
+	}
 	project.UpdatedAt = time.Now()
 
 	// Сохраняем в БД
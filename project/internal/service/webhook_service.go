@@ -0,0 +1,157 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/go-mockingcode/project/internal/model"
+	"github.com/go-mockingcode/project/internal/repository"
+)
+
+type WebhookService struct {
+	projectRepo *repository.ProjectRepository
+	webhookRepo *repository.WebhookRepository
+}
+
+func NewWebhookService(projectRepo *repository.ProjectRepository, webhookRepo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		projectRepo: projectRepo,
+		webhookRepo: webhookRepo,
+	}
+}
+
+// CreateWebhook создает вебхук проекта. Если секрет не передан, он генерируется
+func (s *WebhookService) CreateWebhook(projectID int64, userID int64, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	webhook := &model.Webhook{
+		ProjectID:      projectID,
+		CollectionName: req.CollectionName,
+		URL:            req.URL,
+		Events:         req.Events,
+		Secret:         req.Secret,
+		IsActive:       true,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	if webhook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+
+	if err := s.webhookRepo.CreateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// GetProjectWebhooks возвращает все вебхуки проекта
+func (s *WebhookService) GetProjectWebhooks(projectID int64, userID int64) ([]*model.Webhook, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.GetProjectWebhooks(projectID, false)
+}
+
+// GetActiveWebhooks возвращает включенные вебхуки проекта (для Data service, без проверки owner)
+func (s *WebhookService) GetActiveWebhooks(projectID int64) ([]*model.Webhook, error) {
+	return s.webhookRepo.GetProjectWebhooks(projectID, true)
+}
+
+// GetWebhook возвращает вебхук по ID
+func (s *WebhookService) GetWebhook(webhookID int64, projectID int64, userID int64) (*model.Webhook, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.webhookRepo.GetWebhookByID(webhookID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, errors.New("webhook not found")
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook обновляет вебхук
+func (s *WebhookService) UpdateWebhook(webhookID int64, projectID int64, userID int64, req *model.UpdateWebhookRequest) (*model.Webhook, error) {
+	webhook, err := s.GetWebhook(webhookID, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Обновляем только переданные поля
+	if req.CollectionName != nil {
+		webhook.CollectionName = *req.CollectionName
+	}
+	if req.URL != "" {
+		webhook.URL = req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = req.Events
+	}
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	webhook.UpdatedAt = time.Now()
+
+	if err := s.webhookRepo.UpdateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook удаляет вебхук
+func (s *WebhookService) DeleteWebhook(webhookID int64, projectID int64, userID int64) error {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return err
+	}
+
+	return s.webhookRepo.DeleteWebhook(webhookID, projectID)
+}
+
+// checkProject проверяет, что проект существует и принадлежит пользователю
+func (s *WebhookService) checkProject(projectID int64, userID int64) error {
+	project, err := s.projectRepo.GetProjectByID(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return errors.New("project not found")
+	}
+	return nil
+}
+
+// generateWebhookSecret создает случайный ключ подписи вебхука
+func generateWebhookSecret() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(bytes), nil
+}